@@ -17,6 +17,7 @@ package cmd
 
 import (
 	"context"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -24,6 +25,17 @@ import (
 	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
 )
 
+var (
+	// clusterRoleName is the name of the ClusterRole printed by print-role
+	clusterRoleName string
+
+	// dryRun previews the ClusterRoleBinding subjects left by delete
+	dryRun bool
+
+	// authTimeout limits the whole auth operation, including the namespace validation
+	authTimeout time.Duration
+)
+
 // authCmd represents the auth command
 var authCmd = &cobra.Command{
 	Use:   "auth",
@@ -40,8 +52,10 @@ var authCreateCmd = &cobra.Command{
 namespaces.
 It creates ServiceAccount, RoleBinding or ClusterRoleBinding as per given scope`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.TODO()
-		params, err := configuration.NewParams(ctx, kubeconfig, namespaces, allNamespaces, clusterScope)
+		ctx, cancel := authContext()
+		defer cancel()
+
+		params, err := configuration.NewParamsFromOptions(ctx, authOptions())
 		if err != nil {
 			return err
 		}
@@ -57,18 +71,71 @@ var authDeleteCmd = &cobra.Command{
 namespaces.
 It deletes ServiceAccount, RoleBinding or ClusterRoleBinding as per given scope`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.TODO()
-		params, err := configuration.NewParams(ctx, kubeconfig, namespaces, allNamespaces, clusterScope)
+		ctx, cancel := authContext()
+		defer cancel()
+
+		params, err := configuration.NewParamsFromOptions(ctx, authOptions())
 		if err != nil {
 			return err
 		}
 
+		if dryRun {
+			_, err = auth.DryRunDelete(ctx, params, cmd.OutOrStdout())
+			return err
+		}
+
 		return auth.Delete(ctx, params)
 	},
 }
 
+// authContext returns the context of an auth operation, which times out after --timeout if given.
+func authContext() (context.Context, context.CancelFunc) {
+	if authTimeout > 0 {
+		return context.WithTimeout(context.Background(), authTimeout)
+	}
+
+	return context.WithCancel(context.Background())
+}
+
+// authOptions returns the options of the namespaces and scope given to auth commands.
+func authOptions() *configuration.CollectOptions {
+	return &configuration.CollectOptions{
+		KubeconfigPath:    kubeconfig,
+		Namespaces:        namespaces,
+		ExcludeNamespaces: excludeNamespaces,
+		NamespaceRegex:    namespaceRegex,
+		AllNamespaces:     allNamespaces,
+		ClusterScope:      clusterScope,
+		Verbose:           verbose,
+		DumpConfig:        dumpConfig,
+		LogFormat:         logFormat,
+	}
+}
+
+// authPrintRoleCmd represents the auth print-role command
+var authPrintRoleCmd = &cobra.Command{
+	Use:   "print-role",
+	Short: "print-role command prints the ClusterRole needed by Aerospike cluster",
+	Long: `This command prints the manifest of the ClusterRole bound by the create command, with the rules
+needed by Aerospike cluster, so that it can be reviewed and applied.
+For example:
+akoctl auth print-role | kubectl apply -f -`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return auth.PrintRole(cmd.OutOrStdout(), clusterRoleName)
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(authCmd)
 	authCmd.AddCommand(authCreateCmd)
 	authCmd.AddCommand(authDeleteCmd)
+	authCmd.AddCommand(authPrintRoleCmd)
+
+	authCmd.PersistentFlags().DurationVar(&authTimeout, "timeout", 0,
+		"Time limit of the whole operation, for example 30s, after which it fails instead of hanging against an "+
+			"unresponsive apiserver. 0 means no limit")
+	authDeleteCmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"Print the ClusterRoleBinding subjects before and after deletion without deleting anything")
+	authPrintRoleCmd.Flags().StringVar(&clusterRoleName, "cluster-role", auth.ClusterRoleName,
+		"Name of the printed ClusterRole")
 }
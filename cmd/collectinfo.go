@@ -17,6 +17,11 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -25,7 +30,65 @@ import (
 )
 
 var (
-	path string
+	path           string
+	outputDir      string
+	outputFile     string
+	bundleName     string
+	scope          string
+	fieldSelectors []string
+	nodeSelector   string
+	logSelector    string
+	operatorSel    string
+	targetNode     string
+	aerospikeNodes bool
+	followDuration time.Duration
+	ctxTimeout     time.Duration
+	changedSince   time.Duration
+	interval       time.Duration
+	count          int
+	promRange      time.Duration
+	promURL        string
+	fileMode       string
+	namePrefix     string
+	consolidate    bool
+	singleJSON     bool
+	parallelComp   bool
+	perFileGzip    bool
+	perFileGzipMin int64
+	pageSize       int64
+	perNsArchive   bool
+	logTimestamps  bool
+	sinceRestart   bool
+	keepFullLogs   bool
+	execConfig     bool
+	asinfo         bool
+	asinfoCommands []string
+	asinfoPort     int
+	asinfoUser     string
+	asinfoSecret   string
+	gatewayAPI     bool
+	autoscaler     bool
+	operatorMetric bool
+	strict         bool
+	onlyUnhealthy  bool
+	stripManaged   bool
+	kubeSystem     bool
+	resume         bool
+	preflight      bool
+	postCommand    string
+	extraKindsFile string
+	redactionFile  string
+	defaultRedacts bool
+	maxNsLogBytes  int64
+	logMaxSize     int
+	compression    string
+	format         string
+	logGrep        string
+	redactLogs     []string
+	appendTo       string
+	baseline       string
+	resourceVer    string
+	nameTemplate   string
 )
 
 // collectinfoCmd represents the collectinfo command
@@ -38,19 +101,365 @@ var collectinfoCmd = &cobra.Command{
 * containers logs.
 * events logs.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.TODO()
-		params, err := configuration.NewParams(ctx, kubeconfig, namespaces, allNamespaces, clusterScope)
-		if err != nil {
+		return runCollectInfoCmd(cmd, "")
+	},
+}
+
+// collectinfoClusterCmd represents the collectinfo cluster command
+var collectinfoClusterCmd = &cobra.Command{
+	Use:   "cluster <name>",
+	Short: "collectinfo cluster command collects info of a single AerospikeCluster",
+	Long: `This command collects the given AerospikeCluster and the objects owned by it from the given namespace:
+* aerospikecluster, pods, statefulsets, persistentvolumeclaims, poddisruptionbudgets, configmaps, services objects.
+* persistentvolumes and storageclasses used by its persistentvolumeclaims.
+* priorityclasses used by its pods.
+* containers logs.
+* events of the collected objects.
+For example:
+akoctl collectinfo cluster aerocluster -n aerospike`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCollectInfoCmd(cmd, args[0])
+	},
+}
+
+// runCollectInfoCmd runs the collection, limited to the given AerospikeCluster if clusterName is not empty.
+func runCollectInfoCmd(cmd *cobra.Command, clusterName string) error {
+	// interrupted collection is archived as a truncated bundle
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if outputDir == "" {
+		outputDir = path
+	}
+
+	// summary is printed to stdout unless a file is given, with the logs written to stderr
+	if format == configuration.FormatSummary && outputFile == "" {
+		outputFile = configuration.StdoutOutputFile
+	}
+
+	// fail before creating clients if bundle can not be written
+	if !preflight && outputFile == "" {
+		if err := collectinfo.ValidateOutputPath(outputDir); err != nil {
 			return err
 		}
+	}
 
-		return collectinfo.RunCollectInfo(ctx, params, path)
-	},
+	// --cluster-scope decides the scope when --scope is not given
+	if !cmd.Flags().Changed("scope") {
+		scope = configuration.ScopeNamespace
+		if clusterScope {
+			scope = configuration.ScopeBoth
+		}
+	}
+
+	opts := &configuration.CollectOptions{
+		KubeconfigPath:       kubeconfig,
+		Namespaces:           namespaces,
+		ExcludeNamespaces:    excludeNamespaces,
+		NamespaceRegex:       namespaceRegex,
+		AllNamespaces:        allNamespaces,
+		ClusterScope:         clusterScope,
+		Verbose:              verbose,
+		DumpConfig:           dumpConfig,
+		LogFormat:            logFormat,
+		ClusterName:          clusterName,
+		Scope:                scope,
+		FieldSelectors:       fieldSelectors,
+		NodeSelector:         nodeSelector,
+		LogSelector:          logSelector,
+		OperatorSelector:     operatorSel,
+		TargetNode:           targetNode,
+		AerospikeNodes:       aerospikeNodes,
+		LogGrep:              logGrep,
+		RedactLogs:           redactLogs,
+		KeepFullLogs:         keepFullLogs,
+		ExecConfig:           execConfig,
+		Asinfo:               asinfo,
+		AsinfoCommands:       asinfoCommands,
+		AsinfoPort:           asinfoPort,
+		AsinfoUser:           asinfoUser,
+		AsinfoSecret:         asinfoSecret,
+		GatewayAPI:           gatewayAPI,
+		Autoscaler:           autoscaler,
+		OperatorMetrics:      operatorMetric,
+		Strict:               strict,
+		OnlyIfUnhealthy:      onlyUnhealthy,
+		NamePrefix:           namePrefix,
+		Consolidate:          consolidate,
+		SingleJSON:           singleJSON,
+		ParallelCompress:     parallelComp,
+		PerFileGzip:          perFileGzip,
+		PerFileGzipThreshold: &perFileGzipMin,
+		PageSize:             &pageSize,
+		PerNamespaceArchive:  perNsArchive,
+		IncludeKubeSystem:    kubeSystem,
+		Resume:               resume,
+		BundleName:           bundleName,
+		FollowDuration:       followDuration,
+		ContextTimeout:       ctxTimeout,
+		ChangedSince:         changedSince,
+		Interval:             interval,
+		Count:                count,
+		PrometheusURL:        promURL,
+		PrometheusRange:      promRange,
+		MaxNamespaceLogBytes: maxNsLogBytes,
+		LogTimestamps:        logTimestamps,
+		SinceRestart:         sinceRestart,
+		KeepManagedFields:    !stripManaged,
+		NoDefaultRedaction:   !defaultRedacts,
+		LogMaxSize:           logMaxSize,
+		ResourceVersion:      resourceVer,
+		OutputNameTemplate:   nameTemplate,
+		OutputFile:           outputFile,
+		Compression:          compression,
+		Format:               format,
+		AppendTo:             appendTo,
+		Baseline:             baseline,
+		FileMode:             fileMode,
+		ExtraKindsFile:       extraKindsFile,
+		RedactionRulesFile:   redactionFile,
+	}
+
+	if cmd.Flags().Changed("post-command") {
+		opts.PostCommand = &postCommand
+	}
+
+	params, err := configuration.NewParamsFromOptions(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if preflight {
+		return runPreflight(ctx, params)
+	}
+
+	if params.Format == configuration.FormatSummary {
+		return collectinfo.RunSummary(ctx, params, outputFile)
+	}
+
+	if outputFile != "" {
+		return collectinfo.RunCollectInfoToFile(ctx, params, outputFile)
+	}
+
+	if params.Interval > 0 {
+		return collectinfo.RunCollectInfoOnInterval(ctx, params, outputDir, 0)
+	}
+
+	return collectinfo.RunCollectInfo(ctx, params, outputDir)
 }
 
 func init() {
 	rootCmd.AddCommand(collectinfoCmd)
+	collectinfoCmd.AddCommand(collectinfoClusterCmd)
 
-	collectinfoCmd.Flags().StringVar(&path, "path", "",
+	collectinfoCmd.PersistentFlags().StringVar(&outputDir, "output-dir", "",
+		"Directory where generated tar file will be saved, created if not present")
+	collectinfoCmd.PersistentFlags().StringVar(&path, "path", "",
 		"Absolute path where generated tar file will be saved")
+	_ = collectinfoCmd.PersistentFlags().MarkDeprecated("path", "use --output-dir instead")
+	collectinfoCmd.MarkFlagsMutuallyExclusive("output-dir", "path")
+	collectinfoCmd.PersistentFlags().StringVar(&outputFile, "output-file", "",
+		"Path of the generated tar file with the whole bundle, or - to stream it to stdout with the logs written to "+
+			"stderr, for example to pipe it into tar -tz")
+	collectinfoCmd.PersistentFlags().StringVar(&format, "format", configuration.FormatFull,
+		"Output of the collection, one of full or summary. summary prints only the AerospikeClusters with their "+
+			"health, the pod phases and the warning events of each namespace to stdout, or to output-file if given, "+
+			"without collecting the objects and logs, for a quick first look")
+	collectinfoCmd.PersistentFlags().StringVar(&bundleName, "bundle-name", collectinfo.RootOutputDir,
+		"Name of the collected bundle, used as root directory in the tar file and as tar file name prefix")
+	collectinfoCmd.PersistentFlags().StringVar(&appendTo, "append-to", "",
+		"Existing tar file to which the collection is appended under a directory named after the kubeconfig "+
+			"context, instead of creating a new tar file. Compression of the tar file is used")
+	collectinfoCmd.PersistentFlags().StringVar(&baseline, "baseline", "",
+		"Tar file of a previous collection. Objects which are new or whose resourceVersion or spec changed since "+
+			"then are also written in the delta directory of the bundle, to compare before and after a change")
+	collectinfoCmd.PersistentFlags().StringVar(&compression, "compression", configuration.CompressionGzip,
+		"Compression of the generated tar file, one of gzip or zstd. zstd is faster and smaller for big bundles")
+	collectinfoCmd.PersistentFlags().BoolVar(&parallelComp, "parallel-compress", false,
+		"Compress gzip blocks concurrently using all the CPUs, to speed up compression of big bundles. "+
+			"zstd is always compressed concurrently")
+	collectinfoCmd.PersistentFlags().BoolVar(&perFileGzip, "per-file-gzip", false,
+		"Gzip each log file bigger than per-file-gzip-threshold individually into a .log.gz file in the tar file, "+
+			"so that big logs can be extracted on their own")
+	collectinfoCmd.PersistentFlags().Int64Var(&perFileGzipMin, "per-file-gzip-threshold",
+		configuration.DefaultPerFileGzipThreshold,
+		"Size in bytes above which log files are gzipped individually with per-file-gzip")
+	collectinfoCmd.PersistentFlags().StringVar(&fileMode, "file-mode", "",
+		"Octal permission of the generated tar file and the files in it, for example 0640, readable by the owner. "+
+			"Defaults to 0650 for tar file and 0600 for the files in it")
+	collectinfoCmd.PersistentFlags().StringVar(&scope, "scope", "",
+		"Scope of collected resources, one of namespace, cluster or both. "+
+			"Defaults to both if cluster-scope is set, otherwise namespace")
+	collectinfoCmd.PersistentFlags().StringArrayVar(&fieldSelectors, "field-selector", nil,
+		"Field selector for pods or events in <kind>:<selector> format, where kind is pod or event. "+
+			"For example pod:status.phase!=Running or event:type=Warning. Can be given multiple times")
+	collectinfoCmd.PersistentFlags().StringVar(&nodeSelector, "node-selector", "",
+		"Label selector of the collected nodes, for example node-role.kubernetes.io/aerospike=true. "+
+			"All nodes are collected by default")
+	collectinfoCmd.PersistentFlags().StringVar(&logSelector, "log-selector", "",
+		"Label selector of the pods of all namespaces whose logs are collected in cross_namespace_logs, "+
+			"independent of the given namespaces, for example app=monitoring-agent")
+	collectinfoCmd.PersistentFlags().StringVar(&operatorSel, "operator-selector", "",
+		"Label selector of the operator Deployment, Pods and ServiceAccount for custom installs, for example "+
+			"control-plane=controller-manager. These are located by their standard names by default")
+	collectinfoCmd.PersistentFlags().StringVar(&targetNode, "node", "",
+		"Name of a misbehaving node, whose object, events and the events of its pods reported by its kubelet "+
+			"are collected in k8s_cluster/nodes/<node>")
+	collectinfoCmd.PersistentFlags().BoolVar(&aerospikeNodes, "aerospike-nodes", false,
+		"Collect only the nodes hosting the collected Aerospike pods. Can be combined with node-selector")
+	collectinfoCmd.PersistentFlags().DurationVar(&followDuration, "follow-duration", 0,
+		"Follow the logs of running containers for the given duration, for example 30s, "+
+			"to capture the activity during that window. Logs are not followed by default")
+	collectinfoCmd.PersistentFlags().DurationVar(&ctxTimeout, "context-timeout", 0,
+		"Timeout of the whole collection, for example 10m. Once it expires, no more objects or logs are collected "+
+			"and the data collected till then is archived with a TRUNCATED marker. follow-duration is separate. "+
+			"No timeout by default")
+	collectinfoCmd.PersistentFlags().DurationVar(&interval, "interval", 0,
+		"Re-run the collection on the given interval till interrupted, for example 10m, producing an archive with "+
+			"its own timestamp per run, to catch intermittent issues. Collection is run once by default")
+	collectinfoCmd.PersistentFlags().IntVar(&count, "count", 0,
+		"Number of latest archives kept with interval, older archives produced by the same invocation are removed. "+
+			"All archives are kept by default")
+	collectinfoCmd.PersistentFlags().StringVar(&promURL, "prometheus-url", "",
+		"Address of Prometheus to query the key Aerospike metrics of the given namespaces from, "+
+			"for example http://prometheus.monitoring:9090. Metrics are not collected by default")
+	collectinfoCmd.PersistentFlags().DurationVar(&promRange, "prometheus-range", configuration.DefaultPrometheusRange,
+		"Time range until now of the metrics queried from Prometheus, must be positive")
+	collectinfoCmd.PersistentFlags().BoolVar(&logTimestamps, "log-timestamps", false,
+		"Prefix each line of the container logs with its RFC3339 timestamp, to correlate logs across containers")
+	collectinfoCmd.PersistentFlags().BoolVar(&sinceRestart, "since-restart", false,
+		"Capture the logs of each container since its last start, to focus on the current run after a crash. "+
+			"Logs of previous containers are captured in full")
+	collectinfoCmd.PersistentFlags().StringVar(&logGrep, "log-grep", "",
+		"Regular expression of the container log lines to be captured, for example (?i)(error|warn), "+
+			"to keep the bundle small. All lines are captured by default")
+	collectinfoCmd.PersistentFlags().StringArrayVar(&redactLogs, "redact-logs", configuration.DefaultLogRedactions,
+		"Regular expression of the substrings masked in the container logs, can be repeated. Only the captured "+
+			"groups are masked if the expression has any. Defaults cover common credential formats, "+
+			"--redact-logs= disables the redaction")
+	collectinfoCmd.PersistentFlags().BoolVar(&keepFullLogs, "keep-full-logs", false,
+		"Capture the full container logs in <container name>.full.log along with the lines matching log-grep")
+	collectinfoCmd.PersistentFlags().BoolVar(&execConfig, "exec-config", false,
+		"Exec into the Aerospike server containers to capture the rendered aerospike.conf and asinfo config. "+
+			"Needs create permission on pods/exec")
+	collectinfoCmd.PersistentFlags().BoolVar(&asinfo, "asinfo", false,
+		"Exec into the Aerospike server containers to capture the output of asinfo-commands and asadm info in "+
+			"aerospike_info of each pod. Needs create permission on pods/exec")
+	collectinfoCmd.PersistentFlags().StringSliceVar(&asinfoCommands, "asinfo-commands",
+		configuration.DefaultAsinfoCommands, "Info commands run using asinfo -v with asinfo")
+	collectinfoCmd.PersistentFlags().IntVar(&asinfoPort, "asinfo-port", configuration.DefaultAsinfoPort,
+		"Service port of the Aerospike server queried with asinfo")
+	collectinfoCmd.PersistentFlags().StringVar(&asinfoUser, "asinfo-user", "",
+		"Aerospike user of the commands run with asinfo, if security is enabled. Needs asinfo-password-secret")
+	collectinfoCmd.PersistentFlags().StringVar(&asinfoSecret, "asinfo-password-secret", "",
+		"Secret with the password of asinfo-user in its password key, in the namespace of each Aerospike pod. "+
+			"Needs get permission on secrets")
+	collectinfoCmd.MarkFlagsRequiredTogether("asinfo-user", "asinfo-password-secret")
+	collectinfoCmd.PersistentFlags().BoolVar(&gatewayAPI, "gateway-api", false,
+		"Collect Gateway API Gateways and HTTPRoutes, skipped if Gateway API is not installed in the cluster")
+	collectinfoCmd.PersistentFlags().BoolVar(&autoscaler, "autoscaler", false,
+		"Collect the cluster-autoscaler status ConfigMap and Karpenter NodePools and NodeClaims in "+
+			"k8s_cluster/autoscaler, to debug Pending pods waiting for nodes. Skipped if not installed")
+	collectinfoCmd.PersistentFlags().BoolVar(&operatorMetric, "operator-metrics", false,
+		"Scrape the controller-runtime metrics of each running operator pod once through the pod proxy, "+
+			"in operator_metrics.txt of the pod. Needs get permission on pods/proxy")
+	collectinfoCmd.PersistentFlags().Int64Var(&maxNsLogBytes, "max-namespace-log-bytes", 0,
+		"Maximum bytes of container logs collected per namespace, logs are not captured once it is reached. "+
+			"No limit by default")
+	collectinfoCmd.PersistentFlags().IntVar(&logMaxSize, "log-max-size", 0,
+		"Maximum size in megabytes of the akoctl.log in the bundle, after which it is rotated. "+
+			"Not rotated by default")
+	collectinfoCmd.PersistentFlags().StringVar(&resourceVer, "resource-version", "",
+		"List objects at the given resource version, so that all kinds reflect the same snapshot. "+
+			"Latest objects are listed if it is too old")
+	collectinfoCmd.PersistentFlags().StringVar(&nameTemplate, "output-name-template", "",
+		"Template of the file names of collected objects with {namespace}, {name}, {uid}, {uid8} and {kind} "+
+			"placeholders, for example {namespace}_{name}_{uid8}. Characters unsafe in file names are replaced by _. "+
+			"Defaults to the object name")
+	collectinfoCmd.PersistentFlags().DurationVar(&changedSince, "changed-since", 0,
+		"Collect only objects created or modified within the given duration till now, for example 2h, "+
+			"to reduce noise of stable objects. Pods and their logs are always collected. All objects by default")
+	collectinfoCmd.PersistentFlags().StringVar(&namePrefix, "name-prefix", "",
+		"Collect only objects whose name starts with the given prefix")
+	collectinfoCmd.PersistentFlags().BoolVar(&consolidate, "consolidate", false,
+		"Write all objects of a kind into a single JSON lines file instead of one file per object")
+	collectinfoCmd.PersistentFlags().Int64Var(&pageSize, "page-size", configuration.DefaultPageSize,
+		"Number of objects listed per request, so that namespaces with thousands of objects are listed in pages "+
+			"without stressing the apiserver. 0 lists all the objects at once")
+	collectinfoCmd.PersistentFlags().BoolVar(&singleJSON, "single-json", false,
+		"Write all collected objects into a single cluster_snapshot.json, a map of kind to its objects, "+
+			"instead of one file per object. Container logs are still written per pod")
+	collectinfoCmd.MarkFlagsMutuallyExclusive("single-json", "consolidate")
+	collectinfoCmd.PersistentFlags().BoolVar(&stripManaged, "strip-managed-fields", true,
+		"Remove metadata.managedFields from the collected objects. Set it to false to keep them")
+	collectinfoCmd.PersistentFlags().BoolVar(&perNsArchive, "per-namespace-archive", false,
+		"Write each namespace into its own tar file, along with a tar file for the cluster scoped objects, "+
+			"instead of a single tar file")
+	collectinfoCmd.MarkFlagsMutuallyExclusive("append-to", "per-namespace-archive")
+	collectinfoCmd.PersistentFlags().BoolVar(&kubeSystem, "include-kube-system", false,
+		"Collect DNS and CNI related pods and their logs from kube-system namespace")
+	collectinfoCmd.PersistentFlags().BoolVar(&resume, "resume", false,
+		"Resume an interrupted collection present at the given path, collecting only the remaining objects")
+	// objects of the interrupted collection are not in the snapshot
+	collectinfoCmd.MarkFlagsMutuallyExclusive("single-json", "resume")
+	// delta is built from the object files of the bundle
+	collectinfoCmd.MarkFlagsMutuallyExclusive("baseline", "single-json")
+	collectinfoCmd.MarkFlagsMutuallyExclusive("baseline", "consolidate")
+	collectinfoCmd.PersistentFlags().StringVar(&extraKindsFile, "extra-kinds-file", "",
+		"YAML file with a list of extra kinds to collect, each with group, version, kind, scope "+
+			"(namespace or cluster) and optional namePrefix")
+	collectinfoCmd.PersistentFlags().BoolVar(&defaultRedacts, "default-redactions", true,
+		"Mask the environment variable values of the containers of pods, statefulsets, deployments and "+
+			"aerospikeclusters, and the values and annotations of secrets. Set to false to keep them as they are")
+	collectinfoCmd.PersistentFlags().StringVar(&redactionFile, "redaction-rules-file", "",
+		"YAML file with a list of redaction rules, each with a kind and the paths of its fields to be masked, "+
+			"for example spec.containers[*].env[*].value. Extends the default rules, invalid paths are skipped")
+	collectinfoCmd.PersistentFlags().StringVar(&postCommand, "post-command", "",
+		"Command run after the collection with the archive path, for example \"scp {} user@host:/bundles/\". "+
+			"{} is replaced by the archive path, which is appended if {} is not present. It is run without a shell")
+	collectinfoCmd.PersistentFlags().BoolVar(&preflight, "preflight", false,
+		"Only check connectivity and permissions required for the collection and print a readiness table, "+
+			"without collecting anything")
+	collectinfoCmd.PersistentFlags().BoolVar(&strict, "strict", false,
+		"Fail after archiving the bundle if no AerospikeClusters are found in any of the given namespaces, "+
+			"for automated health gates")
+	// kinds of the units completed by the interrupted run are not listed again
+	collectinfoCmd.MarkFlagsMutuallyExclusive("strict", "resume")
+	collectinfoCmd.PersistentFlags().BoolVar(&onlyUnhealthy, "only-if-unhealthy", false,
+		"Collect only if some AerospikeCluster is unhealthy, for automated capture on failure. Collection is skipped "+
+			"without an archive if all the AerospikeClusters are healthy")
+	// repeated collections can not resume or append into the same bundle
+	collectinfoCmd.MarkFlagsMutuallyExclusive("interval", "resume")
+	collectinfoCmd.MarkFlagsMutuallyExclusive("interval", "append-to")
+	collectinfoCmd.MarkFlagsMutuallyExclusive("interval", "preflight")
+	// streamed bundle is collected in a temporary directory and written as a single archive
+	for _, flag := range []string{"output-dir", "path", "resume", "append-to", "per-namespace-archive", "interval",
+		"post-command", "preflight"} {
+		collectinfoCmd.MarkFlagsMutuallyExclusive("output-file", flag)
+	}
+}
+
+// runPreflight prints the readiness table and fails if any of the checks failed.
+func runPreflight(ctx context.Context, params *configuration.Parameters) error {
+	checks, err := collectinfo.RunPreflight(ctx, params, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+
+	for idx := range checks {
+		if !checks[idx].Allowed {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d preflight checks failed", failed, len(checks))
+	}
+
+	params.Logger.Info("All preflight checks passed")
+
+	return nil
 }
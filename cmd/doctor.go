@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/doctor"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "doctor command checks whether the environment is set up for akoctl",
+	Long: `This command checks the following and prints a checklist with a hint for each failed check:
+* kubeconfig is resolvable.
+* kubernetes cluster is reachable.
+* operator CRDs are installed.
+* operator deployment is present.
+* kubectl is available, which is used by collectinfo to capture the summary.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// an unresolvable kubeconfig is reported by the kubeconfig check
+		if cfg, err := configuration.LoadConfig(kubeconfig); err == nil && dumpConfig {
+			configuration.DumpConfig(os.Stderr, cfg, configuration.CurrentContextName(kubeconfig))
+		}
+
+		checks, err := doctor.Run(context.TODO(), kubeconfig, os.Stdout)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+
+		for idx := range checks {
+			if !checks[idx].Passed {
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -19,18 +19,27 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/version"
 )
 
 var (
-	kubeconfig    string
-	namespaces    []string
-	allNamespaces bool
-	clusterScope  bool
+	kubeconfig        string
+	namespaces        []string
+	excludeNamespaces []string
+	namespaceRegex    string
+	allNamespaces     bool
+	clusterScope      bool
+	verbose           bool
+	dumpConfig        bool
+	logFormat         string
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "akoctl",
-	Short: "A command line tool for Aerospike Kubernetes Operator",
+	Use:     "akoctl",
+	Version: version.Version,
+	Short:   "A command line tool for Aerospike Kubernetes Operator",
 	Long: `A CLI which is used to perform different functions related to Aerospike Kubernetes Operator and 
 Aerospike Kubernetes Operator cluster.
 For example:
@@ -51,6 +60,19 @@ func init() {
 		"Absolute path to the kubeconfig file")
 	rootCmd.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false,
 		"Specify all namespaces present in cluster")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeNamespaces, "exclude-namespaces", nil,
+		"Comma separated list of namespaces to skip when all-namespaces or namespace-regex is set, "+
+			"ignored for explicitly given namespaces")
+	rootCmd.PersistentFlags().StringVar(&namespaceRegex, "namespace-regex", "",
+		"Regular expression of the namespaces to perform operation in, for example ^aerospike-pr-[0-9]+$. "+
+			"Matching namespaces are added to the given namespaces, or selected from all namespaces if it is set")
 	rootCmd.PersistentFlags().BoolVar(&clusterScope, "cluster-scope", true,
 		"Permission to work in cluster scoped mode (operate on cluster scoped resources like ClusterRoleBinding)")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false,
+		"Enable debug logs, including method, URL, status and duration of every API request")
+	rootCmd.PersistentFlags().BoolVar(&dumpConfig, "dump-config", false,
+		"Print the resolved host, context and auth method of the Kubernetes client config to stderr, "+
+			"without any secret")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", configuration.LogFormatConsole,
+		"Format of the console logs, one of console or json. json is suited for log aggregation")
 }
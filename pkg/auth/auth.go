@@ -18,6 +18,7 @@ package auth
 import (
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 
 	"go.uber.org/zap"
@@ -28,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
 	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
@@ -44,29 +46,13 @@ func Create(ctx context.Context, params *configuration.Parameters) error {
 	subjects := make([]interface{}, 0, len(params.Namespaces))
 
 	for ns := range params.Namespaces {
-		sa := &corev1.ServiceAccount{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      ServiceAccountName,
-				Namespace: ns,
-			},
+		nsFound, err := ensureServiceAccount(ctx, params, ns)
+		if err != nil {
+			return err
 		}
 
-		// Create SA and check namespace existence
-		if err := params.K8sClient.Create(ctx, sa); err != nil {
-			if errors.IsNotFound(err) {
-				params.Logger.Error(fmt.Sprintf("namespace: %s not found, skipping RBAC resources", ns))
-				continue
-			}
-
-			if !errors.IsAlreadyExists(err) {
-				return err
-			}
-
-			params.Logger.Info("Resource already exists, skipping", zap.String("kind", internal.ServiceAccountKind),
-				zap.String("name", ServiceAccountName), zap.String("namespace", ns))
-		} else {
-			params.Logger.Info("Created resource", zap.String("kind", internal.ServiceAccountKind),
-				zap.String("name", ServiceAccountName), zap.String("namespace", ns))
+		if !nsFound {
+			continue
 		}
 
 		sub := map[string]interface{}{
@@ -103,9 +89,49 @@ func Create(ctx context.Context, params *configuration.Parameters) error {
 		subjects)
 }
 
+// ensureServiceAccount creates the ServiceAccount in the given namespace if it is missing. It returns false if the
+// namespace is not found.
+func ensureServiceAccount(ctx context.Context, params *configuration.Parameters, ns string) (bool, error) {
+	// Namespaces may be deleted after they are validated, or be given without validation by API users. Creating a
+	// resource in a missing namespace does not reliably fail with NotFound, so check it explicitly
+	if err := params.K8sClient.Get(ctx, types.NamespacedName{Name: ns}, &corev1.Namespace{}); err != nil {
+		if errors.IsNotFound(err) {
+			params.Logger.Warn(fmt.Sprintf("namespace: %s not found, skipping RBAC resources", ns))
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ServiceAccountName,
+			Namespace: ns,
+		},
+	}
+
+	if err := params.K8sClient.Create(ctx, sa); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return false, err
+		}
+
+		params.Logger.Info("Resource already exists, skipping", zap.String("kind", internal.ServiceAccountKind),
+			zap.String("name", ServiceAccountName), zap.String("namespace", ns))
+
+		return true, nil
+	}
+
+	params.Logger.Info("Created resource", zap.String("kind", internal.ServiceAccountKind),
+		zap.String("name", ServiceAccountName), zap.String("namespace", ns))
+
+	return true, nil
+}
+
+// createOrUpdateBinding creates the binding with the given subjects, or adds the missing subjects to the binding if it
+// already exists.
 func createOrUpdateBinding(
 	ctx context.Context, params *configuration.Parameters, gvk schema.GroupVersionKind,
-	nsNm types.NamespacedName, subjects interface{},
+	nsNm types.NamespacedName, subjects []interface{},
 ) error {
 	unstruct := &unstructured.Unstructured{}
 	unstruct.SetGroupVersionKind(gvk)
@@ -122,41 +148,49 @@ func createOrUpdateBinding(
 	unstruct.Object["roleRef"] = roleRef
 
 	if err := params.K8sClient.Create(ctx, unstruct); err != nil {
-		if errors.IsAlreadyExists(err) {
-			params.Logger.Info("Resource already exists, trying to update", zap.String("kind", gvk.Kind),
-				zap.String("name", nsNm.Name), zap.String("namespace", nsNm.Namespace))
-
-			currentResource := &unstructured.Unstructured{}
-			currentResource.SetGroupVersionKind(gvk)
-
-			if gErr := params.K8sClient.Get(ctx, nsNm, currentResource); gErr != nil {
-				return gErr
-			}
+		if !errors.IsAlreadyExists(err) {
+			return err
+		}
 
-			if !reflect.DeepEqual(currentResource.Object["roleRef"], unstruct.Object["roleRef"]) {
-				return fmt.Errorf("%s: %s already exists with different roleRe,"+
-					"can't update roleRef to %s", gvk.Kind, nsNm.Name, ClusterRoleName)
-			}
+		params.Logger.Info("Resource already exists, trying to update", zap.String("kind", gvk.Kind),
+			zap.String("name", nsNm.Name), zap.String("namespace", nsNm.Namespace))
 
-			if !reflect.DeepEqual(currentResource.Object["subjects"], unstruct.Object["subjects"]) {
-				currentResource.Object["subjects"] = mergeSubjects(currentResource.Object["subjects"].([]interface{}),
-					unstruct.Object["subjects"].([]interface{}))
+		currentResource := &unstructured.Unstructured{}
+		currentResource.SetGroupVersionKind(gvk)
 
-				if uErr := params.K8sClient.Update(ctx, currentResource); uErr != nil {
-					return uErr
-				}
+		if gErr := params.K8sClient.Get(ctx, nsNm, currentResource); gErr != nil {
+			return gErr
+		}
 
-				params.Logger.Info("Updated resource", zap.String("kind", gvk.Kind),
-					zap.String("name", nsNm.Name), zap.String("namespace", nsNm.Namespace))
+		if !reflect.DeepEqual(currentResource.Object["roleRef"], unstruct.Object["roleRef"]) {
+			return fmt.Errorf("%s: %s already exists with different roleRe,"+
+				"can't update roleRef to %s", gvk.Kind, nsNm.Name, ClusterRoleName)
+		}
 
-				return nil
-			}
+		// Binding may have been left without any subject
+		currentSubjects, _, sErr := unstructured.NestedSlice(currentResource.Object, "subjects")
+		if sErr != nil {
+			return sErr
+		}
 
+		merged := mergeSubjects(currentSubjects, subjects)
+		if len(merged) == len(currentSubjects) {
 			params.Logger.Info("Update not required, skipping", zap.String("kind", gvk.Kind),
 				zap.String("name", nsNm.Name), zap.String("namespace", nsNm.Namespace))
 
 			return nil
 		}
+
+		currentResource.Object["subjects"] = merged
+
+		if uErr := params.K8sClient.Update(ctx, currentResource); uErr != nil {
+			return uErr
+		}
+
+		params.Logger.Info("Updated resource", zap.String("kind", gvk.Kind),
+			zap.String("name", nsNm.Name), zap.String("namespace", nsNm.Namespace))
+
+		return nil
 	}
 
 	params.Logger.Info("Created resource", zap.String("kind", gvk.Kind),
@@ -172,7 +206,7 @@ func mergeSubjects(baseSub, patchSub []interface{}) []interface{} {
 		var matched bool
 
 		for baseIdx := range baseSub {
-			if reflect.DeepEqual(baseSub[baseIdx].(map[string]interface{}), patchSub[patchIdx].(map[string]interface{})) {
+			if sameSubject(baseSub[baseIdx], patchSub[patchIdx]) {
 				matched = true
 				break
 			}
@@ -190,6 +224,24 @@ func mergeSubjects(baseSub, patchSub []interface{}) []interface{} {
 	return baseSub
 }
 
+// sameSubject compares the kind, name and namespace of the subjects, ignoring the defaulted fields.
+func sameSubject(base, patch interface{}) bool {
+	baseMap, ok := base.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	patchMap := patch.(map[string]interface{})
+
+	for _, key := range []string{"kind", "name", "namespace"} {
+		if baseMap[key] != patchMap[key] {
+			return false
+		}
+	}
+
+	return true
+}
+
 func Delete(ctx context.Context, params *configuration.Parameters) error {
 	for ns := range params.Namespaces {
 		// Delete serviceAccount
@@ -207,6 +259,11 @@ func Delete(ctx context.Context, params *configuration.Parameters) error {
 		}
 	}
 
+	// Failed deletions are only logged, but none of them is done once the context is canceled or timed out
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Return from here if namespace scope
 	if !params.ClusterScope {
 		return nil
@@ -219,17 +276,7 @@ func Delete(ctx context.Context, params *configuration.Parameters) error {
 		return err
 	}
 
-	// Removed subject entries for the given namespaces
-	filtered := make([]v1.Subject, 0, len(crb.Subjects))
-
-	for _, sub := range crb.Subjects {
-		if sub.Kind == internal.ServiceAccountKind &&
-			sub.Name == ServiceAccountName && params.Namespaces.Has(sub.Namespace) {
-			continue
-		}
-
-		filtered = append(filtered, sub)
-	}
+	filtered := remainingSubjects(crb.Subjects, params.Namespaces)
 
 	if len(filtered) == 0 {
 		deleteResource(
@@ -255,6 +302,69 @@ func Delete(ctx context.Context, params *configuration.Parameters) error {
 	return params.K8sClient.Update(ctx, crb)
 }
 
+// DryRunDelete prints the ClusterRoleBinding subjects before and after removing the given namespaces, without
+// updating or deleting any resource. It returns the subjects that a Delete would leave.
+func DryRunDelete(ctx context.Context, params *configuration.Parameters, w io.Writer) ([]v1.Subject, error) {
+	if !params.ClusterScope {
+		params.Logger.Info("ClusterRoleBinding is not used in namespace scope, no subjects to preview")
+		return nil, nil
+	}
+
+	crb := &v1.ClusterRoleBinding{}
+	if err := params.K8sClient.Get(ctx, types.NamespacedName{
+		Name: ClusterRoleBindingName,
+	}, crb); err != nil {
+		return nil, err
+	}
+
+	filtered := remainingSubjects(crb.Subjects, params.Namespaces)
+
+	if _, err := fmt.Fprintf(w, "%s: %s\n", internal.ClusterRoleBindingKind, ClusterRoleBindingName); err != nil {
+		return nil, err
+	}
+
+	if err := printSubjects(w, "Current subjects", crb.Subjects); err != nil {
+		return nil, err
+	}
+
+	if len(filtered) == 0 {
+		_, err := fmt.Fprintf(w, "Remaining subjects: none, %s would be deleted\n", internal.ClusterRoleBindingKind)
+		return filtered, err
+	}
+
+	return filtered, printSubjects(w, "Remaining subjects", filtered)
+}
+
+// remainingSubjects returns the subjects left after removing the ServiceAccount entries of the given namespaces.
+func remainingSubjects(subjects []v1.Subject, namespaces sets.Set[string]) []v1.Subject {
+	filtered := make([]v1.Subject, 0, len(subjects))
+
+	for _, sub := range subjects {
+		if sub.Kind == internal.ServiceAccountKind &&
+			sub.Name == ServiceAccountName && namespaces.Has(sub.Namespace) {
+			continue
+		}
+
+		filtered = append(filtered, sub)
+	}
+
+	return filtered
+}
+
+func printSubjects(w io.Writer, title string, subjects []v1.Subject) error {
+	if _, err := fmt.Fprintf(w, "%s:\n", title); err != nil {
+		return err
+	}
+
+	for _, sub := range subjects {
+		if _, err := fmt.Fprintf(w, "  %s %s/%s\n", sub.Kind, sub.Namespace, sub.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func deleteResource(
 	ctx context.Context, params *configuration.Parameters, gvk schema.GroupVersionKind,
 	nsNm types.NamespacedName) {
@@ -16,12 +16,21 @@ limitations under the License.
 package auth_test
 
 import (
+	"bytes"
+	"context"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
 
 	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/auth"
 	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
@@ -61,10 +70,206 @@ var _ = Describe("Auth", func() {
 		})
 	})
 
+	Context("Partial state", func() {
+		It("Should create the binding when only the ServiceAccount is present", func() {
+			for _, clusterScope := range []bool{false, true} {
+				sa := &v1.ServiceAccount{}
+				sa.Name = auth.ServiceAccountName
+				sa.Namespace = namespace
+				Expect(k8sClient.Create(testCtx, sa)).To(Succeed())
+
+				testCreateRbac([]string{namespace}, clusterScope)
+				testDeleteRbac([]string{namespace}, clusterScope, true)
+			}
+		})
+
+		It("Should re-create the ServiceAccount when only the binding is present", func() {
+			for _, clusterScope := range []bool{false, true} {
+				testCreateRbac([]string{namespace}, clusterScope)
+
+				sa := &v1.ServiceAccount{}
+				sa.Name = auth.ServiceAccountName
+				sa.Namespace = namespace
+				Expect(k8sClient.Delete(testCtx, sa)).To(Succeed())
+
+				testCreateRbac([]string{namespace}, clusterScope)
+				testDeleteRbac([]string{namespace}, clusterScope, true)
+			}
+		})
+
+		It("Should add the subject when the binding is present without subjects", func() {
+			crb := &rbac.ClusterRoleBinding{}
+			crb.Name = auth.ClusterRoleBindingName
+			crb.RoleRef = rbac.RoleRef{
+				APIGroup: rbac.GroupName,
+				Kind:     internal.ClusterRoleKind,
+				Name:     auth.ClusterRoleName,
+			}
+			Expect(k8sClient.Create(testCtx, crb)).To(Succeed())
+
+			testCreateRbac([]string{namespace}, true)
+			testDeleteRbac([]string{namespace}, true, true)
+		})
+	})
+
+	Context("Namespace check", func() {
+		It("Should skip RBAC resources for a missing namespace", func() {
+			missingNs := "missing-ns"
+
+			// namespaces are set directly, as ValidateNamespaces drops the missing ones, like a namespace deleted
+			// after the validation
+			params := &configuration.Parameters{
+				K8sClient:  k8sClient,
+				Logger:     configuration.InitializeConsoleLogger(),
+				Namespaces: sets.New(namespace, missingNs),
+			}
+			Expect(auth.Create(testCtx, params)).To(Succeed())
+
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{
+				Namespace: namespace, Name: auth.ServiceAccountName}, &v1.ServiceAccount{})).To(Succeed())
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{
+				Namespace: namespace, Name: auth.RoleBindingName}, &rbac.RoleBinding{})).To(Succeed())
+
+			err := k8sClient.Get(testCtx, types.NamespacedName{
+				Namespace: missingNs, Name: auth.ServiceAccountName}, &v1.ServiceAccount{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			err = k8sClient.Get(testCtx, types.NamespacedName{
+				Namespace: missingNs, Name: auth.RoleBindingName}, &rbac.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			testDeleteRbac([]string{namespace}, false, true)
+		})
+
+		It("Should keep a pre-existing ServiceAccount as it is", func() {
+			sa := &v1.ServiceAccount{}
+			sa.Name = auth.ServiceAccountName
+			sa.Namespace = namespace
+			sa.Labels = map[string]string{"app": "pre-existing"}
+			Expect(k8sClient.Create(testCtx, sa)).To(Succeed())
+
+			testCreateRbac([]string{namespace}, false)
+
+			current := &v1.ServiceAccount{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{
+				Namespace: namespace, Name: auth.ServiceAccountName}, current)).To(Succeed())
+			Expect(current.UID).To(Equal(sa.UID))
+			Expect(current.Labels).To(Equal(sa.Labels))
+
+			testDeleteRbac([]string{namespace}, false, true)
+		})
+	})
+
+	Context("Dry run delete", func() {
+		It("Should preview the subjects left by delete without changing the ClusterRoleBinding", func() {
+			defaultNs := "default"
+			testCreateRbac([]string{namespace, defaultNs}, true)
+
+			params, err := testutils.NewTestParams(testCtx, k8sClient, nil, []string{defaultNs}, false, true)
+			Expect(err).NotTo(HaveOccurred())
+
+			before := &rbac.ClusterRoleBinding{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: auth.ClusterRoleBindingName}, before)).To(Succeed())
+
+			buf := &bytes.Buffer{}
+			preview, err := auth.DryRunDelete(testCtx, params, buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(ContainSubstring("Current subjects:"))
+			Expect(buf.String()).To(ContainSubstring("Remaining subjects:"))
+			Expect(preview).To(HaveLen(len(before.Subjects) - 1))
+
+			By("Checking nothing is changed by the dry run")
+			after := &rbac.ClusterRoleBinding{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: auth.ClusterRoleBindingName}, after)).To(Succeed())
+			Expect(after.Subjects).To(Equal(before.Subjects))
+
+			sa := &v1.ServiceAccount{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{
+				Namespace: defaultNs, Name: auth.ServiceAccountName}, sa)).To(Succeed())
+
+			By("Checking the preview matches the real delete")
+			testDeleteRbac([]string{defaultNs}, true, false)
+
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: auth.ClusterRoleBindingName}, after)).To(Succeed())
+			Expect(after.Subjects).To(Equal(preview))
+
+			testDeleteRbac([]string{namespace}, true, true)
+		})
+	})
+
+	Context("Print role", func() {
+		It("Should print a valid ClusterRole with the required rules", func() {
+			buf := &bytes.Buffer{}
+			Expect(auth.PrintRole(buf, "aerospike-cluster-review")).To(Succeed())
+
+			clusterRole := &rbac.ClusterRole{}
+			Expect(yaml.UnmarshalStrict(buf.Bytes(), clusterRole)).To(Succeed())
+			Expect(clusterRole.Kind).To(Equal(internal.ClusterRoleKind))
+			Expect(clusterRole.APIVersion).To(Equal(rbac.SchemeGroupVersion.String()))
+			Expect(clusterRole.Name).To(Equal("aerospike-cluster-review"))
+			Expect(clusterRole.Rules).To(Equal(auth.ClusterRoleRules))
+
+			By("Applying the printed ClusterRole")
+			Expect(k8sClient.Create(testCtx, clusterRole)).To(Succeed())
+			Expect(k8sClient.Delete(testCtx, clusterRole)).To(Succeed())
+		})
+	})
+
+	Context("Timeout", func() {
+		// blockingClient does not respond till the context of the request is done, like an unresponsive apiserver
+		blockingClient := func() client.Client {
+			block := func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+
+			return interceptor.NewClient(fake.NewClientBuilder().Build(), interceptor.Funcs{
+				Get: func(ctx context.Context, _ client.WithWatch, _ client.ObjectKey, _ client.Object,
+					_ ...client.GetOption) error {
+					return block(ctx)
+				},
+				Create: func(ctx context.Context, _ client.WithWatch, _ client.Object, _ ...client.CreateOption) error {
+					return block(ctx)
+				},
+				Update: func(ctx context.Context, _ client.WithWatch, _ client.Object, _ ...client.UpdateOption) error {
+					return block(ctx)
+				},
+				Delete: func(ctx context.Context, _ client.WithWatch, _ client.Object, _ ...client.DeleteOption) error {
+					return block(ctx)
+				},
+			})
+		}
+
+		It("Should return at the deadline against an unresponsive apiserver", func() {
+			for _, clusterScope := range []bool{false, true} {
+				params, err := testutils.NewTestParams(testCtx, k8sClient, nil, []string{namespace}, false,
+					clusterScope)
+				Expect(err).NotTo(HaveOccurred())
+
+				params.K8sClient = blockingClient()
+
+				for _, operation := range []func(context.Context, *configuration.Parameters) error{
+					auth.Create, auth.Delete,
+				} {
+					ctx, cancel := context.WithTimeout(testCtx, 200*time.Millisecond)
+					start := time.Now()
+
+					Expect(operation(ctx, params)).To(MatchError(context.DeadlineExceeded))
+					Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+
+					cancel()
+				}
+			}
+		})
+	})
+
 	Context("Wrong kubeconfig path", func() {
 		It("Should fail when wrong kubeconfig path is given", func() {
-			_, err := configuration.NewParams(testCtx, "wrongpath", []string{namespace},
-				false, false)
+			_, err := configuration.NewParamsFromOptions(testCtx, &configuration.CollectOptions{
+				KubeconfigPath: "wrongpath",
+				Namespaces:     []string{namespace},
+				LogFormat:      configuration.LogFormatConsole,
+			})
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("wrongpath: no such file or directory"))
 		})
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"io"
+
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// ClusterRoleRules are the rules of the ClusterRole bound to ServiceAccountName, needed by the Aerospike cluster
+// pods to read their pod, node and service details and the Aerospike resources.
+var ClusterRoleRules = []v1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods", "nodes", "services"},
+		Verbs:     []string{"get", "list"},
+	},
+	{
+		APIGroups: []string{"asdb.aerospike.com"},
+		Resources: []string{"*"},
+		Verbs:     []string{"*"},
+	},
+}
+
+// NewClusterRole returns the ClusterRole of the given name with ClusterRoleRules.
+func NewClusterRole(name string) *v1.ClusterRole {
+	return &v1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       internal.ClusterRoleKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Rules:      ClusterRoleRules,
+	}
+}
+
+// PrintRole writes the YAML manifest of the ClusterRole of the given name, so that it can be reviewed and applied.
+func PrintRole(w io.Writer, name string) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(NewClusterRole(name))
+	if err != nil {
+		return err
+	}
+
+	// zero creation timestamp is serialized as null, which is not a part of the manifest
+	unstructured.RemoveNestedField(obj, "metadata", "creationTimestamp")
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const AdmissionFailuresFile = "admission_failures.txt"
+
+// admissionDeniedRegex matches the message of a request denied by an admission webhook, capturing the webhook name
+var admissionDeniedRegex = regexp.MustCompile(`admission webhook "([^"]+)" denied the request`)
+
+// admissionFailureReasons are the reasons of controller events for objects which could not be created or updated,
+// like pods of a StatefulSet rejected by a webhook or a quota.
+var admissionFailureReasons = sets.New("FailedCreate", "FailedUpdate")
+
+// admissionFailure is an event of an object rejected at admission.
+type admissionFailure struct {
+	lastSeen time.Time
+	object   string
+	reason   string
+	webhook  string
+	message  string
+	count    int32
+}
+
+// captureAdmissionFailures writes AdmissionFailuresFile with the events of the given namespace reporting requests
+// rejected at admission, along with the object the event is about and the rejecting webhook.
+func captureAdmissionFailures(ctx context.Context, params *configuration.Parameters, state *collectionState, ns,
+	rootOutputPath string) error {
+	events, err := params.ClientSet.CoreV1().Events(ns).List(ctx,
+		metav1.ListOptions{FieldSelector: params.FieldSelectors[internal.EventKind]})
+	if err != nil {
+		state.failures.record(params.Logger, internal.EventKind, ns, "",
+			fmt.Errorf("could not list events for admission failures: %v", err))
+		return nil
+	}
+
+	var failures []admissionFailure
+
+	for idx := range events.Items {
+		if failure, ok := eventAdmissionFailure(&events.Items[idx]); ok {
+			failures = append(failures, failure)
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	params.Logger.Warn("Found requests rejected at admission", zap.String("namespace", ns),
+		zap.Int("events", len(failures)))
+
+	return populateScraperDir(formatAdmissionFailures(failures), filepath.Join(rootOutputPath, AdmissionFailuresFile))
+}
+
+// eventAdmissionFailure returns the admission failure reported by the given event, if any.
+func eventAdmissionFailure(event *corev1.Event) (admissionFailure, bool) {
+	message := strings.TrimSpace(event.Message)
+
+	var webhook string
+
+	if match := admissionDeniedRegex.FindStringSubmatch(message); match != nil {
+		webhook = match[1]
+	} else if !admissionFailureReasons.Has(event.Reason) ||
+		!(strings.Contains(message, "is forbidden") || strings.Contains(message, "denied")) {
+		return admissionFailure{}, false
+	}
+
+	return admissionFailure{
+		lastSeen: eventTime(event),
+		object:   event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+		reason:   event.Reason,
+		webhook:  webhook,
+		message:  message,
+		count:    event.Count,
+	}, true
+}
+
+// formatAdmissionFailures formats the admission failures in a table, latest first.
+func formatAdmissionFailures(failures []admissionFailure) []byte {
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].lastSeen.After(failures[j].lastSeen)
+	})
+
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "LAST SEEN\tOBJECT\tREASON\tWEBHOOK\tCOUNT\tMESSAGE")
+
+	for idx := range failures {
+		failure := &failures[idx]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", failure.lastSeen.Format(time.RFC3339), failure.object,
+			failure.reason, valueOrNone(failure.webhook), failure.count, failure.message)
+	}
+
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
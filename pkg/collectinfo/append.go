@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+// DefaultAppendDir is the directory of the appended collection in the archive, if kubeconfig context is not known.
+const DefaultAppendDir = "default"
+
+// invalidAppendDirChars are replaced in the context name to use it as directory name in the archive.
+var invalidAppendDirChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// AppendDirName returns the directory of the collection appended to an existing archive, named after the
+// kubeconfig context so that collections of several clusters are kept apart.
+func AppendDirName(params *configuration.Parameters) string {
+	if params.ContextName == "" {
+		return DefaultAppendDir
+	}
+
+	return invalidAppendDirChars.ReplaceAllString(params.ContextName, "_")
+}
+
+// appendArchive adds the given bundle directory, relative to pathToStore, into the archive given in params under
+// AppendDirName. Compressed streams can not be appended in place, so the archive is rewritten into a temporary
+// file with its existing entries followed by the bundle, which then replaces the archive.
+func appendArchive(params *configuration.Parameters, pathToStore, bundleDir string) (archive string, err error) {
+	archive = params.AppendTo
+	prefix := AppendDirName(params)
+
+	info, err := os.Stat(archive)
+	if err != nil {
+		return "", err
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(archive), "."+filepath.Base(archive)+"-*")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err != nil {
+			tmpFile.Close()
+			os.Remove(tmpFile.Name())
+		}
+	}()
+
+	compression := archiveCompression(archive)
+
+	zw, err := newCompressor(tmpFile, compression, params.ParallelCompress, params.PerFileGzip)
+	if err != nil {
+		return "", err
+	}
+
+	tw := tar.NewWriter(zw)
+
+	if err = copyArchive(archive, compression, tw, prefix+"/"+bundleDir+"/"); err != nil {
+		return "", err
+	}
+
+	if err = addToTar(tw, pathToStore, bundleDir, prefix); err != nil {
+		return "", err
+	}
+
+	if err = tw.Close(); err != nil {
+		return "", err
+	}
+
+	if err = zw.Close(); err != nil {
+		return "", err
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		return "", err
+	}
+
+	if err = os.Chmod(tmpFile.Name(), info.Mode().Perm()); err != nil {
+		return "", err
+	}
+
+	return archive, os.Rename(tmpFile.Name(), archive)
+}
+
+// copyArchive copies all the entries of the given archive into the tar. It fails if the archive already has an
+// entry under the given prefix, which would be duplicated by the appended bundle.
+func copyArchive(archive, compression string, tw *tar.Writer, prefix string) error {
+	file, err := os.Open(filepath.Clean(archive))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader
+
+	if compression == configuration.CompressionZstd {
+		zr, zErr := zstd.NewReader(file)
+		if zErr != nil {
+			return zErr
+		}
+		defer zr.Close()
+
+		reader = zr
+	} else {
+		gr, gErr := gzip.NewReader(file)
+		if gErr != nil {
+			return gErr
+		}
+		defer gr.Close()
+
+		reader = gr
+	}
+
+	tarReader := tar.NewReader(reader)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(header.Name+"/", prefix) {
+			return fmt.Errorf("archive %s already has a collection in %s", archive, strings.TrimSuffix(prefix, "/"))
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(tw, tarReader); err != nil { //nolint:gosec // entries of an archive written by akoctl
+			return err
+		}
+	}
+}
+
+// archiveCompression returns the compression of the given archive as per its suffix.
+func archiveCompression(archive string) string {
+	if strings.HasSuffix(archive, ZstdArchiveSuffix) {
+		return configuration.CompressionZstd
+	}
+
+	return configuration.CompressionGzip
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const (
+	// AerospikeInfoDir has the output of the info commands of an Aerospike server, in the directory of its pod.
+	AerospikeInfoDir = "aerospike_info"
+	// AsadmInfoFile has the output of asadm info, which summarizes the network, namespaces and sets of the cluster.
+	AsadmInfoFile = "asadm_info.txt"
+	// AsinfoPasswordKey is the key of the password in the asinfo password Secret, like in the Secrets of the
+	// access control users of an AerospikeCluster.
+	AsinfoPasswordKey = "password"
+)
+
+// AsinfoFileName returns the file in AerospikeInfoDir with the output of the given info command.
+func AsinfoFileName(command string) string {
+	return strings.Trim(unsafeFileNameChars.ReplaceAllString(command, "_"), "_.") + ".txt"
+}
+
+// asinfoConfigScript runs the command given as its arguments with --config-file of a temporary file, which has the
+// aerospike tools config read from stdin. The file is removed once the command exits.
+const asinfoConfigScript = `f=$(mktemp) && trap 'rm -f "$f"' EXIT && cat > "$f" && "$@" --config-file "$f"`
+
+// captureAsinfo runs the info commands of params and asadm info in the Aerospike server container of the given pod,
+// and writes their output in AerospikeInfoDir of the pod directory. The password of params.AsinfoUser is read from
+// params.AsinfoSecret in the namespace of the pod, and is given to the commands in an aerospike tools config file
+// streamed through stdin, so that it is not part of the exec request URL or the command line of the processes in
+// the container. Commands which fail are recorded in the report.
+func captureAsinfo(ctx context.Context, params *configuration.Parameters, state *collectionState, pod *corev1.Pod,
+	podDir string) error {
+	args := []string{"-p", strconv.Itoa(params.AsinfoPort)}
+
+	var toolsConfig []byte
+
+	if params.AsinfoUser != "" {
+		secret, err := params.ClientSet.CoreV1().Secrets(pod.Namespace).Get(ctx, params.AsinfoSecret,
+			metav1.GetOptions{})
+		if err != nil {
+			state.failures.record(params.Logger, internal.SecretKind, pod.Namespace, params.AsinfoSecret,
+				fmt.Errorf("could not read asinfo password: %v", err))
+
+			return nil
+		}
+
+		password, ok := secret.Data[AsinfoPasswordKey]
+		if !ok {
+			state.failures.record(params.Logger, internal.SecretKind, pod.Namespace, params.AsinfoSecret,
+				fmt.Errorf("could not read asinfo password: key %s not found", AsinfoPasswordKey))
+
+			return nil
+		}
+
+		toolsConfig = asinfoToolsConfig(params.AsinfoUser, string(password))
+	}
+
+	infoDir := filepath.Join(podDir, AerospikeInfoDir)
+	if err := os.MkdirAll(infoDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	for _, command := range params.AsinfoCommands {
+		if err := runAsinfoCommand(ctx, params, state, pod, append(append([]string{"asinfo"}, args...), "-v", command),
+			toolsConfig, filepath.Join(infoDir, AsinfoFileName(command))); err != nil {
+			return err
+		}
+	}
+
+	return runAsinfoCommand(ctx, params, state, pod, append(append([]string{"asadm"}, args...), "-e", "info"),
+		toolsConfig, filepath.Join(infoDir, AsadmInfoFile))
+}
+
+// asinfoToolsConfig returns the aerospike tools config, in TOML, with the given credentials of the cluster.
+func asinfoToolsConfig(user, password string) []byte {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+
+	return []byte(fmt.Sprintf("[cluster]\nuser = \"%s\"\npassword = \"%s\"\n", quote.Replace(user),
+		quote.Replace(password)))
+}
+
+// runAsinfoCommand runs the given command in the Aerospike server container of the pod and writes its output in the
+// given file. If the tools config is given, the command is run through asinfoConfigScript with the config in stdin.
+func runAsinfoCommand(ctx context.Context, params *configuration.Parameters, state *collectionState, pod *corev1.Pod,
+	command []string, toolsConfig []byte, file string) error {
+	execCommand := command
+	if toolsConfig != nil {
+		execCommand = append([]string{"sh", "-c", asinfoConfigScript, "sh"}, command...)
+	}
+
+	output, err := execInContainer(ctx, params, pod.Namespace, pod.Name, AerospikeServerContainer, execCommand,
+		toolsConfig)
+	if err != nil {
+		state.failures.record(params.Logger, internal.PodKind, pod.Namespace, pod.Name,
+			fmt.Errorf("could not run %q in container %s: %v", strings.Join(command, " "), AerospikeServerContainer,
+				err))
+
+		return nil
+	}
+
+	return populateScraperDir(output, file)
+}
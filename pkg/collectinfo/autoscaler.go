@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const (
+	// AutoscalerDir has the node provisioning status of cluster-autoscaler and Karpenter, in the cluster scope.
+	AutoscalerDir = "autoscaler"
+	// AutoscalerStatusFile has the status reported by cluster-autoscaler in its status ConfigMap.
+	AutoscalerStatusFile = "cluster_autoscaler_status.txt"
+	// AutoscalerStatusConfigMap is the ConfigMap in which cluster-autoscaler reports its status.
+	AutoscalerStatusConfigMap = "cluster-autoscaler-status"
+	// autoscalerStatusKey is the key of the status in AutoscalerStatusConfigMap
+	autoscalerStatusKey = "status"
+	karpenterGroup      = "karpenter.sh"
+)
+
+// karpenterVersions are the served versions of the Karpenter kinds, in order of preference
+var karpenterVersions = []string{"v1", "v1beta1"}
+
+// karpenterKindDirNames are the directories of the Karpenter kinds in AutoscalerDir
+var karpenterKindDirNames = map[string]string{
+	internal.NodePoolKind:  "nodepools",
+	internal.NodeClaimKind: "nodeclaims",
+}
+
+// captureAutoscaler captures the status ConfigMap of cluster-autoscaler and the Karpenter NodePools and NodeClaims
+// in AutoscalerDir of the cluster scope, which explain why nodes are not provisioned for Pending pods.
+// Each of them is skipped if not present in the cluster.
+func captureAutoscaler(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	rootOutputPath string) error {
+	autoscalerDir := filepath.Join(rootOutputPath, ClusterScopedDir, AutoscalerDir)
+	if err := os.MkdirAll(autoscalerDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := captureAutoscalerStatus(ctx, params, state, autoscalerDir); err != nil {
+		return err
+	}
+
+	for _, kind := range []string{internal.NodePoolKind, internal.NodeClaimKind} {
+		if err := captureKarpenterKind(ctx, params, state, kind, autoscalerDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// captureAutoscalerStatus captures AutoscalerStatusConfigMap from kube-system, along with its status in
+// AutoscalerStatusFile.
+func captureAutoscalerStatus(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	autoscalerDir string) error {
+	logger := params.Logger
+
+	configMap, err := params.ClientSet.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx,
+		AutoscalerStatusConfigMap, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("cluster-autoscaler status not found, skipping", zap.String("configMap",
+				AutoscalerStatusConfigMap), zap.String("namespace", metav1.NamespaceSystem))
+			return nil
+		}
+
+		state.failures.record(logger, internal.ConfigMapKind, metav1.NamespaceSystem, AutoscalerStatusConfigMap, err)
+
+		return nil
+	}
+
+	stripManagedFields(params, configMap)
+
+	data, err := yaml.Marshal(configMap)
+	if err != nil {
+		return err
+	}
+
+	if err := populateScraperDir(data, filepath.Join(autoscalerDir, AutoscalerStatusConfigMap+FileSuffix)); err != nil {
+		return err
+	}
+
+	if status, ok := configMap.Data[autoscalerStatusKey]; ok {
+		if err := populateScraperDir([]byte(status), filepath.Join(autoscalerDir, AutoscalerStatusFile)); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Successfully saved cluster-autoscaler status")
+
+	return nil
+}
+
+// captureKarpenterKind captures the objects of the given Karpenter kind, using the first served version of
+// karpenterVersions. It is skipped if Karpenter is not installed.
+func captureKarpenterKind(ctx context.Context, params *configuration.Parameters, state *collectionState, kind,
+	autoscalerDir string) error {
+	logger := params.Logger
+
+	for _, version := range karpenterVersions {
+		objects := &unstructured.UnstructuredList{}
+		objects.SetGroupVersionKind(schema.GroupVersionKind{Group: karpenterGroup, Version: version, Kind: kind})
+
+		if err := params.K8sClient.List(ctx, objects); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+
+			state.failures.record(logger, kind, "", "", err)
+
+			return nil
+		}
+
+		kindDir := filepath.Join(autoscalerDir, karpenterKindDirNames[kind])
+		if err := os.MkdirAll(kindDir, os.ModePerm); err != nil {
+			return err
+		}
+
+		for idx := range objects.Items {
+			stripManagedFields(params, &objects.Items[idx])
+
+			if err := serializeAndWrite(params, state, objects.Items[idx], kindDir); err != nil {
+				state.failures.record(logger, kind, "", objects.Items[idx].GetName(), err)
+			}
+		}
+
+		logger.Info("Successfully saved ", zap.String("kind", kind), zap.Int("number of objects",
+			len(objects.Items)))
+
+		return nil
+	}
+
+	logger.Info("Kind not served by the cluster, skipping", zap.String("kind", kind))
+
+	return nil
+}
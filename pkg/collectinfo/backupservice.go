@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// StatusFile contains the status of an object collected along with its related objects.
+const StatusFile = "status.yaml"
+
+var backupServiceGVK = schema.GroupVersionKind{
+	Group:   OperatorGroup,
+	Version: "v1beta1",
+	Kind:    internal.BackupServiceKind,
+}
+
+// captureBackupServices captures each AerospikeBackupService in its own directory along with its status and
+// the ConfigMaps owned by it, which contain the backup service config generated by the operator.
+// It is skipped if AerospikeBackupService CRD is not installed.
+func captureBackupServices(ctx context.Context, params *configuration.Parameters, state *collectionState, ns,
+	rootOutputPath string) error {
+	logger := params.Logger
+
+	services := &unstructured.UnstructuredList{}
+	services.SetGroupVersionKind(backupServiceGVK)
+
+	if err := params.K8sClient.List(ctx, services, client.InNamespace(ns)); err != nil {
+		if meta.IsNoMatchError(err) {
+			logger.Info("Kind not served by the cluster, skipping", zap.String("kind", internal.BackupServiceKind))
+			return nil
+		}
+
+		logger.Error("Not able to list ", zap.String("kind", internal.BackupServiceKind), zap.Error(err))
+
+		return err
+	}
+
+	if len(services.Items) == 0 {
+		logger.Info("No resource found in namespace", zap.String("kind", internal.BackupServiceKind),
+			zap.String("namespace", ns))
+		return nil
+	}
+
+	configMaps, err := params.ClientSet.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Error("Not able to list ", zap.String("kind", internal.ConfigMapKind), zap.Error(err))
+		return err
+	}
+
+	count := 0
+
+	for idx := range services.Items {
+		service := &services.Items[idx]
+		if !strings.HasPrefix(service.GetName(), params.NamePrefix) {
+			continue
+		}
+
+		serviceDir := filepath.Join(rootOutputPath, KindDirNames[internal.BackupServiceKind], service.GetName())
+		if err := os.MkdirAll(serviceDir, os.ModePerm); err != nil {
+			return err
+		}
+
+		state.changeHistories.record(internal.BackupServiceKind, service)
+		state.crossRefs.record(internal.BackupServiceKind, service)
+		stripManagedFields(params, service)
+
+		if err := serializeAndWrite(params, state, *service, serviceDir); err != nil {
+			return err
+		}
+
+		if status, ok := service.Object["status"]; ok {
+			statusData, err := yaml.Marshal(status)
+			if err != nil {
+				return err
+			}
+
+			if err := populateScraperDir(statusData, filepath.Join(serviceDir, StatusFile)); err != nil {
+				return err
+			}
+		}
+
+		state.ownerGraph.record(internal.BackupServiceKind, service)
+
+		configMapDir := filepath.Join(serviceDir, KindDirNames[internal.ConfigMapKind])
+
+		for cmIdx := range configMaps.Items {
+			configMap := &configMaps.Items[cmIdx]
+			if !isOwnedBy(configMap, service) {
+				continue
+			}
+
+			if err := os.MkdirAll(configMapDir, os.ModePerm); err != nil {
+				return err
+			}
+
+			stripManagedFields(params, configMap)
+
+			data, err := yaml.Marshal(configMap)
+			if err != nil {
+				return err
+			}
+
+			if err := populateScraperDir(data, filepath.Join(configMapDir, configMap.Name+FileSuffix)); err != nil {
+				return err
+			}
+
+			state.ownerGraph.record(internal.ConfigMapKind, configMap)
+		}
+
+		count++
+	}
+
+	logger.Info("Successfully saved ", zap.String("kind", internal.BackupServiceKind),
+		zap.Int("number of objects", count), zap.String("namespace", ns))
+
+	return nil
+}
+
+// isOwnedBy returns true if the owner is present in the owner references of the given object.
+func isOwnedBy(obj, owner metav1.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+// DeltaDir has the objects which are new or changed since the baseline bundle, in the same tree as the bundle.
+const DeltaDir = "delta"
+
+// baselineObject is the resourceVersion and spec of an object file of a bundle.
+type baselineObject struct {
+	spec            interface{}
+	resourceVersion string
+}
+
+// loadBaseline reads the object files of the given baseline archive, keyed by their path relative to the bundle
+// directory, like k8s_namespaces/<namespace>/<kind>/<name>.yaml. Objects of DeltaDir of the baseline are ignored.
+// If the archive has several appended bundles, the first object of each path is kept.
+func loadBaseline(archive string) (map[string]baselineObject, error) {
+	file, err := os.Open(filepath.Clean(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader
+
+	if archiveCompression(archive) == configuration.CompressionZstd {
+		zr, zErr := zstd.NewReader(file)
+		if zErr != nil {
+			return nil, zErr
+		}
+		defer zr.Close()
+
+		reader = zr
+	} else {
+		gr, gErr := gzip.NewReader(file)
+		if gErr != nil {
+			return nil, gErr
+		}
+		defer gr.Close()
+
+		reader = gr
+	}
+
+	tarReader := tar.NewReader(reader)
+	objects := map[string]baselineObject{}
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return objects, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid baseline archive %s: %v", archive, err)
+		}
+
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(header.Name, FileSuffix) {
+			continue
+		}
+
+		key, ok := bundleObjectPath(path.Clean(header.Name))
+		if !ok {
+			continue
+		}
+
+		if _, ok := objects[key]; ok {
+			continue
+		}
+
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+
+		if obj, ok := parseBundleObject(data); ok {
+			objects[key] = obj
+		}
+	}
+}
+
+// bundleObjectPath returns the given archive path relative to its bundle directory, which starts with
+// NamespaceScopedDir or ClusterScopedDir. It returns false for the other files and the files of DeltaDir.
+func bundleObjectPath(name string) (string, bool) {
+	parts := strings.Split(name, "/")
+
+	for idx, part := range parts {
+		if idx > 0 && parts[idx-1] == DeltaDir {
+			return "", false
+		}
+
+		if part == NamespaceScopedDir || part == ClusterScopedDir {
+			return strings.Join(parts[idx:], "/"), true
+		}
+	}
+
+	return "", false
+}
+
+// parseBundleObject returns the resourceVersion and spec of the given YAML object. It returns false if the data is
+// not an object, like the summaries written along with the objects.
+func parseBundleObject(data []byte) (baselineObject, bool) {
+	var obj struct {
+		Spec     interface{} `json:"spec"`
+		Metadata struct {
+			Name            string `json:"name"`
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}
+
+	if err := yaml.Unmarshal(data, &obj); err != nil || obj.Metadata.Name == "" {
+		return baselineObject{}, false
+	}
+
+	return baselineObject{spec: obj.Spec, resourceVersion: obj.Metadata.ResourceVersion}, true
+}
+
+// writeDelta copies the collected objects which are new, or whose resourceVersion or spec changed since the given
+// baseline, in DeltaDir of the bundle with the same path. Objects are matched by their path in the bundle, so the
+// baseline is expected to be collected with the same output-name-template. It returns the number of copied objects.
+func writeDelta(params *configuration.Parameters, baseline map[string]baselineObject,
+	rootOutputPath string) (int, error) {
+	var count int
+
+	for _, dir := range []string{NamespaceScopedDir, ClusterScopedDir} {
+		err := filepath.WalkDir(filepath.Join(rootOutputPath, dir), func(file string, d os.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return filepath.SkipDir
+				}
+
+				return err
+			}
+
+			if d.IsDir() || !strings.HasSuffix(file, FileSuffix) {
+				return nil
+			}
+
+			data, err := os.ReadFile(filepath.Clean(file))
+			if err != nil {
+				return err
+			}
+
+			obj, ok := parseBundleObject(data)
+			if !ok {
+				return nil
+			}
+
+			relPath, err := filepath.Rel(rootOutputPath, file)
+			if err != nil {
+				return err
+			}
+
+			if base, ok := baseline[filepath.ToSlash(relPath)]; ok && base.resourceVersion == obj.resourceVersion &&
+				reflect.DeepEqual(base.spec, obj.spec) {
+				return nil
+			}
+
+			deltaFile := filepath.Join(rootOutputPath, DeltaDir, relPath)
+			if err := os.MkdirAll(filepath.Dir(deltaFile), os.ModePerm); err != nil {
+				return err
+			}
+
+			count++
+
+			return populateScraperDir(data, deltaFile)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	params.Logger.Info("Saved objects changed since the baseline", zap.String("baseline", params.Baseline),
+		zap.Int("number of objects", count))
+
+	return count, nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const (
+	// CertExpiryFile lists the certificates of the operator client cert Secrets of the AerospikeClusters of a
+	// namespace, with their expiry.
+	CertExpiryFile = "cert_expiry.txt"
+	// CertExpiryWarning is the time before expiry from which a certificate is reported as expiring
+	CertExpiryWarning = 30 * 24 * time.Hour
+)
+
+// operatorCertSources are the fields of the Secrets of the operator client certificates in the AerospikeCluster spec
+var operatorCertSources = [][]string{
+	{"spec", "operatorClientCert", "secretCertSource"},
+	{"spec", "operatorClientCert", "secretCertSource", "caCertsSource"},
+}
+
+// certSecretRef is a Secret with the operator client certificates of an AerospikeCluster.
+type certSecretRef struct {
+	cluster   string
+	field     string
+	namespace string
+	name      string
+}
+
+// certExpiryState records the operator client cert Secrets of the collected AerospikeClusters of each namespace.
+type certExpiryState struct {
+	refs  map[string][]certSecretRef
+	mutex sync.Mutex
+}
+
+func newCertExpiryState() *certExpiryState {
+	return &certExpiryState{refs: map[string][]certSecretRef{}}
+}
+
+// record records the operator client cert Secrets of the given AerospikeCluster. Secrets without a namespace are
+// in the namespace of the cluster, as defaulted by the operator.
+func (s *certExpiryState) record(kind string, obj *unstructured.Unstructured) {
+	if kind != internal.AerospikeClusterKind {
+		return
+	}
+
+	var refs []certSecretRef
+
+	for _, fields := range operatorCertSources {
+		name, _, _ := unstructured.NestedString(obj.Object, append(fields, "secretName")...)
+		if name == "" {
+			continue
+		}
+
+		namespace, _, _ := unstructured.NestedString(obj.Object, append(fields, "secretNamespace")...)
+		if namespace == "" {
+			namespace = obj.GetNamespace()
+		}
+
+		refs = append(refs, certSecretRef{cluster: obj.GetName(), field: strings.Join(fields, "."),
+			namespace: namespace, name: name})
+	}
+
+	if len(refs) == 0 {
+		return
+	}
+
+	s.mutex.Lock()
+	s.refs[obj.GetNamespace()] = append(s.refs[obj.GetNamespace()], refs...)
+	s.mutex.Unlock()
+}
+
+// write reads the recorded Secrets of the given namespace and writes the subject and expiry of each certificate in
+// them in CertExpiryFile. Only the certificates are parsed, private keys and other keys of the Secrets are skipped.
+// Secrets which can not be read are recorded in the report.
+func (s *certExpiryState) write(ctx context.Context, params *configuration.Parameters, failures *failureRecorder, ns,
+	rootOutputPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	refs := s.refs[ns]
+	if len(refs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "CLUSTER\tFIELD\tSECRET\tKEY\tSUBJECT\tNOT AFTER\tSTATUS")
+
+	for idx := range refs {
+		ref := &refs[idx]
+		secretName := ref.namespace + "/" + ref.name
+
+		secret, err := params.ClientSet.CoreV1().Secrets(ref.namespace).Get(ctx, ref.name, metav1.GetOptions{})
+		if err != nil {
+			status := "not found"
+			if !apierrors.IsNotFound(err) {
+				status = "failed"
+
+				failures.record(params.Logger, internal.SecretKind, ref.namespace, ref.name, err)
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t<none>\t<none>\t<none>\t%s\n", ref.cluster, ref.field, secretName, status)
+
+			continue
+		}
+
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			for _, cert := range parseCertificates(secret.Data[key]) {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", ref.cluster, ref.field, secretName, key,
+					cert.Subject.String(), cert.NotAfter.UTC().Format(time.RFC3339), certExpiryStatus(cert, now))
+			}
+		}
+	}
+
+	_ = w.Flush()
+
+	return populateScraperDir(buf.Bytes(), filepath.Join(rootOutputPath, CertExpiryFile))
+}
+
+// parseCertificates returns the certificates of the PEM CERTIFICATE blocks of the given data. Other blocks, like
+// private keys, are not parsed.
+func parseCertificates(data []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+
+		certs = append(certs, cert)
+	}
+}
+
+// certExpiryStatus returns whether the given certificate is expired, expiring within CertExpiryWarning, not yet
+// valid or valid at the given time, with the time left till its expiry.
+func certExpiryStatus(cert *x509.Certificate, now time.Time) string {
+	left := cert.NotAfter.Sub(now)
+
+	switch {
+	case left <= 0:
+		return fmt.Sprintf("expired %s ago", (-left).Truncate(time.Hour))
+	case now.Before(cert.NotBefore):
+		return "not yet valid"
+	case left < CertExpiryWarning:
+		return fmt.Sprintf("expiring in %s", left.Truncate(time.Hour))
+	default:
+		return fmt.Sprintf("valid for %s", left.Truncate(time.Hour))
+	}
+}
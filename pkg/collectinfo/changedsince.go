@@ -0,0 +1,38 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// isChangedSince returns true if the given object is created or modified since changedSinceTime. Modification time
+// is the latest time of its managedFields entries.
+func isChangedSince(state *collectionState, obj metav1.Object) bool {
+	if state.changedSinceTime.IsZero() {
+		return true
+	}
+
+	lastChanged := obj.GetCreationTimestamp().Time
+
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(lastChanged) {
+			lastChanged = entry.Time.Time
+		}
+	}
+
+	return !lastChanged.Before(state.changedSinceTime)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// ChangeHistoryFile is written in the directory of each collected Aerospike CR, with the managers which changed it
+// and its events.
+const ChangeHistoryFile = "change_history.txt"
+
+// changeHistory is the managedFields of a collected Aerospike CR, recorded before these are stripped.
+type changeHistory struct {
+	kind     string
+	name     string
+	uid      types.UID
+	managers []metav1.ManagedFieldsEntry
+}
+
+// changeHistoryState records the change history of the collected Aerospike CRs of each namespace.
+type changeHistoryState struct {
+	histories map[string][]changeHistory
+	mutex     sync.Mutex
+}
+
+func newChangeHistoryState() *changeHistoryState {
+	return &changeHistoryState{histories: map[string][]changeHistory{}}
+}
+
+// record records the managedFields of the given Aerospike CR of the given kind.
+func (s *changeHistoryState) record(kind string, obj metav1.Object) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.histories[obj.GetNamespace()] = append(s.histories[obj.GetNamespace()], changeHistory{
+		kind:     kind,
+		name:     obj.GetName(),
+		uid:      obj.GetUID(),
+		managers: obj.GetManagedFields(),
+	})
+}
+
+// write writes ChangeHistoryFile in the directory of each recorded Aerospike CR of the given namespace. The CRs are
+// still written without their events if events can not be listed.
+func (s *changeHistoryState) write(ctx context.Context, params *configuration.Parameters, ns,
+	rootOutputPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.histories[ns]) == 0 {
+		return nil
+	}
+
+	events, err := listEventsByUID(ctx, params.ClientSet, ns, params.FieldSelectors[internal.EventKind])
+	if err != nil {
+		params.Logger.Warn("Not able to list events, skipping them in change history", zap.String("namespace", ns),
+			zap.Error(err))
+	}
+
+	for idx := range s.histories[ns] {
+		history := &s.histories[ns][idx]
+
+		historyDir := filepath.Join(rootOutputPath, KindDirNames[history.kind], history.name)
+		if err := os.MkdirAll(historyDir, os.ModePerm); err != nil {
+			return err
+		}
+
+		if err := populateScraperDir(history.format(events[history.uid]),
+			filepath.Join(historyDir, ChangeHistoryFile)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// format formats the managers of the CR in a table with the latest update first, followed by its events.
+func (h *changeHistory) format(events []corev1.Event) []byte {
+	managers := append([]metav1.ManagedFieldsEntry{}, h.managers...)
+
+	sort.SliceStable(managers, func(i, j int) bool {
+		return managedFieldsTime(&managers[i]).After(managedFieldsTime(&managers[j]))
+	})
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s: %s\n\n", h.kind, h.name)
+	buf.WriteString("Managers:\n")
+
+	if len(managers) == 0 {
+		buf.WriteString("<none>\n")
+	} else {
+		w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "MANAGER\tOPERATION\tSUBRESOURCE\tAPI VERSION\tTIME")
+
+		for idx := range managers {
+			entry := &managers[idx]
+
+			updateTime := "<none>"
+			if entry.Time != nil {
+				updateTime = entry.Time.UTC().Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", valueOrNone(entry.Manager), entry.Operation,
+				valueOrNone(entry.Subresource), entry.APIVersion, updateTime)
+		}
+
+		_ = w.Flush()
+	}
+
+	buf.WriteString("\nEvents:\n")
+
+	if len(events) == 0 {
+		buf.WriteString("<none>\n")
+	} else {
+		buf.Write(formatEvents(events))
+	}
+
+	return buf.Bytes()
+}
+
+func managedFieldsTime(entry *metav1.ManagedFieldsEntry) time.Time {
+	if entry.Time == nil {
+		return time.Time{}
+	}
+
+	return entry.Time.Time
+}
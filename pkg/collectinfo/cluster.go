@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// ClusterNameLabel is set by the operator on the objects it creates for an AerospikeCluster, like pods and PVCs.
+const ClusterNameLabel = "aerospike.com/cr"
+
+// clusterModeClusterKinds are the cluster scoped kinds collected when collection is limited to a cluster
+var clusterModeClusterKinds = sets.New(internal.PVKind, internal.SCKind, internal.VolumeAttachmentKind,
+	internal.PriorityClassKind)
+
+// isClusterObject returns true if the given namespace scoped object belongs to the AerospikeCluster given in params.
+// Objects are selected if they are owned by an already collected object or have the cluster name label.
+// Operator ConfigMaps are always selected.
+func isClusterObject(params *configuration.Parameters, state *collectionState, kind string, obj metav1.Object) bool {
+	switch kind {
+	case internal.AerospikeClusterKind:
+		return obj.GetName() == params.ClusterName
+	case internal.ConfigMapKind:
+		if isOperatorConfigMap(obj.GetName()) {
+			return true
+		}
+	}
+
+	return obj.GetLabels()[ClusterNameLabel] == params.ClusterName || state.ownerGraph.hasCollectedOwner(obj)
+}
+
+// isOwnerSelected returns true if the objects of given kind are selected using their owners, so that they are
+// collected after the other kinds. In cluster mode, all kinds except AerospikeCluster are selected using owners
+// and are collected one by one in the order of gvkListNSScoped, so that owners are always collected first.
+func isOwnerSelected(params *configuration.Parameters, kind string) bool {
+	if params.ClusterName != "" {
+		return kind != internal.AerospikeClusterKind
+	}
+
+	return ownerSelectedKinds.Has(kind)
+}
+
+// clusterSummaryArgs returns the kubectl arguments to select objects of the given kind belonging to the cluster.
+func clusterSummaryArgs(params *configuration.Parameters, kind string) []string {
+	if kind == internal.AerospikeClusterKind {
+		return []string{params.ClusterName}
+	}
+
+	return []string{"-l", ClusterNameLabel + "=" + params.ClusterName}
+}
+
+// clusterEvents returns the formatted events of the collected objects of the given namespace.
+func clusterEvents(ctx context.Context, params *configuration.Parameters, state *collectionState, ns string) ([]byte,
+	error) {
+	eventsByUID, err := listEventsByUID(ctx, params.ClientSet, ns, params.FieldSelectors[internal.EventKind])
+	if err != nil {
+		return nil, err
+	}
+
+	var events []corev1.Event
+
+	for uid, objEvents := range eventsByUID {
+		if state.ownerGraph.isCollected(uid) {
+			events = append(events, objEvents...)
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	return formatEvents(events), nil
+}
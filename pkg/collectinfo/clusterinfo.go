@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/discovery"
+	clientversion "k8s.io/client-go/pkg/version"
+)
+
+const ClusterInfoFile = "cluster_info.txt"
+
+// CaptureClusterInfo writes the kubernetes server version, client version and the API groups served by the cluster
+// to the cluster info file in the given directory.
+func CaptureClusterInfo(logger *zap.Logger, discoveryClient discovery.DiscoveryInterface, rootOutputPath string) error {
+	if err := os.MkdirAll(rootOutputPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	var info strings.Builder
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		logger.Warn("Not able to fetch server version", zap.Error(err))
+		info.WriteString("Server Version: unknown\n")
+	} else {
+		fmt.Fprintf(&info, "Server Version: %s\n", serverVersion.GitVersion)
+		fmt.Fprintf(&info, "Server Platform: %s\n", serverVersion.Platform)
+		fmt.Fprintf(&info, "Server Go Version: %s\n", serverVersion.GoVersion)
+	}
+
+	fmt.Fprintf(&info, "Client Version: %s\n", clientversion.Get().GitVersion)
+
+	groups, err := discoveryClient.ServerGroups()
+	if err != nil {
+		logger.Warn("Not able to fetch server API groups", zap.Error(err))
+	}
+
+	if groups != nil {
+		info.WriteString("\nAPI Groups:\n")
+
+		sort.Slice(groups.Groups, func(i, j int) bool {
+			return groups.Groups[i].Name < groups.Groups[j].Name
+		})
+
+		for idx := range groups.Groups {
+			group := &groups.Groups[idx]
+			versions := make([]string, 0, len(group.Versions))
+
+			for _, version := range group.Versions {
+				versions = append(versions, version.Version)
+			}
+
+			name := group.Name
+			if name == "" {
+				name = "core"
+			}
+
+			fmt.Fprintf(&info, "%s: %s (preferred %s)\n", name, strings.Join(versions, ","),
+				group.PreferredVersion.Version)
+		}
+	}
+
+	if err := populateScraperDir([]byte(info.String()), filepath.Join(rootOutputPath, ClusterInfoFile)); err != nil {
+		return err
+	}
+
+	logger.Info("Successfully saved cluster info")
+
+	return nil
+}
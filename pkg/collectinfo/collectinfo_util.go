@@ -20,25 +20,33 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
@@ -52,6 +60,8 @@ const (
 	ClusterScopedDir        = "k8s_cluster"
 	LogFileName             = "akoctl.log"
 	FileSuffix              = ".yaml"
+	JSONLinesSuffix         = ".jsonl"
+	FullLogSuffix           = ".full.log"
 	MutatingWebhookPrefix   = "maerospikecluster.kb.io"
 	ValidatingWebhookPrefix = "vaerospikecluster.kb.io"
 	MutatingWebhookName     = "aerospike-operator-mutating-webhook-configuration"
@@ -59,381 +69,1790 @@ const (
 	SummaryDir              = "summary"
 	SummaryFile             = "summary.txt"
 	EventsFile              = "events.txt"
+	LogsTruncatedFile       = "logs_truncated.txt"
+	TruncatedFile           = "TRUNCATED"
+	GzipArchiveSuffix       = ".tar.gzip"
+	ZstdArchiveSuffix       = ".tar.zst"
+	KubeSystemNamespace     = "kube-system"
 	kubectlCMD              = "kubectl"
+	// ArchivePathPlaceholder is replaced by the archive path in the post command
+	ArchivePathPlaceholder = "{}"
+
+	// maxConcurrentKinds is the number of kinds collected concurrently within a namespace
+	maxConcurrentKinds = 4
+	// parallelCompressBlockSize is the size of the gzip blocks compressed concurrently
+	parallelCompressBlockSize = 1 << 20
 )
 
+// archiveTimeLayout is the layout of the timestamp in the archive names
+const archiveTimeLayout = "20060102_150405"
+
 var (
-	currentTime = time.Now().Format("20060102_150405")
-	TarName     = RootOutputDir + "_" + currentTime + ".tar.gzip"
-	pvcNameSet  = sets.Set[string]{}
+	currentTime = time.Now().Format(archiveTimeLayout)
+	TarName     = RootOutputDir + "_" + currentTime + GzipArchiveSuffix
 )
 
+// BundleDirName returns the name of the directory in which objects are collected before archiving.
+func BundleDirName(params *configuration.Parameters) string {
+	if params.BundleName != "" {
+		return params.BundleName
+	}
+
+	return RootOutputDir
+}
+
+// BundleTarName returns the name of the generated tar file, with suffix as per the compression.
+func BundleTarName(params *configuration.Parameters) string {
+	return BundlePartTarName(params, "")
+}
+
+// BundlePartTarName returns the name of the tar file generated for the given part of the bundle, which is a
+// namespace or ClusterScopedDir when each namespace is archived separately.
+func BundlePartTarName(params *configuration.Parameters, part string) string {
+	name := RootOutputDir
+	if params.BundleName != "" {
+		name = params.BundleName
+	}
+
+	if part != "" {
+		name += "_" + part
+	}
+
+	suffix := GzipArchiveSuffix
+	if params.Compression == configuration.CompressionZstd {
+		suffix = ZstdArchiveSuffix
+	}
+
+	return name + "_" + archiveTimestamp(params) + suffix
+}
+
+// archiveTimestamp returns the timestamp of the archive names, which is the run time of the collection if set,
+// otherwise the process start time.
+func archiveTimestamp(params *configuration.Parameters) string {
+	if params.RunTime.IsZero() {
+		return currentTime
+	}
+
+	return params.RunTime.Format(archiveTimeLayout)
+}
+
 func RunCollectInfo(ctx context.Context, params *configuration.Parameters, path string) error {
-	rootOutputPath := filepath.Join(path, RootOutputDir)
-	if err := os.Mkdir(rootOutputPath, os.ModePerm); err != nil {
-		return err
+	_, err := runCollectInfo(ctx, params, path)
+
+	return err
+}
+
+// runCollectInfo runs RunCollectInfo and returns the paths of the generated archives. Errors of the collection are
+// logged and the bundle directory is left in path, so that the collection can be resumed.
+func runCollectInfo(ctx context.Context, params *configuration.Parameters, path string) ([]string, error) {
+	if collect, err := shouldCollect(ctx, params); err != nil || !collect {
+		return nil, err
+	}
+
+	if err := ValidateOutputPath(path); err != nil {
+		return nil, err
+	}
+
+	rootOutputPath := filepath.Join(path, BundleDirName(params))
+	if params.Resume {
+		if err := os.MkdirAll(rootOutputPath, os.ModePerm); err != nil {
+			return nil, err
+		}
+	} else if err := os.Mkdir(rootOutputPath, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	detachLog, err := attachCollectionLog(params, rootOutputPath)
+	if err != nil {
+		return nil, err
 	}
 
-	params.Logger = AttachFileLogger(params.Logger, filepath.Join(rootOutputPath, LogFileName))
+	// log is detached before archiving, this restores the logger if the collection fails before that
+	defer detachLog()
 
-	if err := CollectInfo(ctx, params, path); err != nil {
+	archives, err := collectInfo(ctx, params, path, nil, detachLog)
+	if err != nil {
 		params.Logger.Error("Not able to collect object info", zap.String("err", err.Error()))
+
+		// health gates rely on the result of strict mode, the bundle is still archived
+		if errors.Is(err, ErrMissingKinds) {
+			return archives, err
+		}
 	}
 
-	return nil
+	return archives, nil
+}
+
+// ValidateOutputPath creates the given output directory if not present and verifies that it is writable by
+// creating and removing a temporary file in it. Empty path is the current directory.
+func ValidateOutputPath(path string) error {
+	if path == "" {
+		path = "."
+	}
+
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return fmt.Errorf("not able to create output path %s: %v", path, err)
+	}
+
+	file, err := os.CreateTemp(path, ".akoctl-write-check-*")
+	if err != nil {
+		return fmt.Errorf("output path %s is not writable: %v", path, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(file.Name())
 }
 
-func AttachFileLogger(logger *zap.Logger, path string) *zap.Logger {
+// attachCollectionLog tees the logger of params into LogFileName of the bundle. The returned function restores the
+// logger and closes the file, so that the whole log is archived and repeated collections do not log into earlier
+// bundles. It can be called more than once.
+func attachCollectionLog(params *configuration.Parameters, rootOutputPath string) (func() error, error) {
+	logger := params.Logger
+
+	fileLogger, logFile, err := AttachFileLogger(logger, filepath.Join(rootOutputPath, LogFileName), params.LogMaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	params.Logger = fileLogger
+
+	var once sync.Once
+
+	return func() error {
+		once.Do(func() {
+			params.Logger = logger
+			err = logFile.Close()
+		})
+
+		return err
+	}, nil
+}
+
+// AttachFileLogger tees the given logger into the given file. If maxSizeMB is given, the file is rotated once it
+// reaches maxSizeMB megabytes, keeping the rotated files next to it. The returned closer syncs and closes the file,
+// the returned logger must not be used after that.
+func AttachFileLogger(logger *zap.Logger, path string, maxSizeMB int) (*zap.Logger, io.Closer, error) {
 	cfg := zap.NewProductionEncoderConfig()
 	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
 	fileEncoder := zapcore.NewJSONEncoder(cfg)
-	logFile, _ := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gocritic // file permission
+
+	var logFile io.WriteCloser
+
+	if maxSizeMB > 0 {
+		logFile = &lumberjack.Logger{Filename: path, MaxSize: maxSizeMB}
+	} else {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gocritic // file permission
+		if err != nil {
+			return nil, nil, err
+		}
+
+		logFile = file
+	}
+
+	syncer := zapcore.AddSync(logFile)
+
+	// Keep debug logs in file too if those are enabled for the given logger
 	defaultLogLevel := zapcore.InfoLevel
+	if logger.Core().Enabled(zapcore.DebugLevel) {
+		defaultLogLevel = zapcore.DebugLevel
+	}
+
 	core := zapcore.NewTee(
-		zapcore.NewCore(fileEncoder, zapcore.AddSync(logFile), defaultLogLevel),
+		zapcore.NewCore(fileEncoder, zapcore.Lock(syncer), defaultLogLevel),
 	)
 
 	updateCore := zap.WrapCore(func(c zapcore.Core) zapcore.Core {
 		return zapcore.NewTee(c, core)
 	})
 
-	return logger.WithOptions(updateCore)
+	return logger.WithOptions(updateCore), &syncCloser{syncer: syncer, closer: logFile}, nil
+}
+
+// syncCloser syncs the log file before closing it.
+type syncCloser struct {
+	syncer zapcore.WriteSyncer
+	closer io.Closer
+}
+
+func (c *syncCloser) Close() error {
+	if err := c.syncer.Sync(); err != nil {
+		return err
+	}
+
+	return c.closer.Close()
 }
 
 func CollectInfo(ctx context.Context, params *configuration.Parameters, path string) error {
-	rootOutputPath := filepath.Join(path, RootOutputDir)
+	_, err := collectInfo(ctx, params, path, nil, nil)
 
-	params.Logger.Info("Capturing namespace scoped objects info")
+	return err
+}
 
-	for ns := range params.Namespaces {
-		objOutputDir := filepath.Join(rootOutputPath, NamespaceScopedDir, ns)
-		if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
-			return err
+// collectInfo collects the bundle in the given path, and archives it in w if given, otherwise in tar files in path,
+// returning their paths.
+// detachLog, if given, detaches the collection log file before archiving, so that the whole log is archived.
+func collectInfo(ctx context.Context, params *configuration.Parameters, path string, w io.Writer,
+	detachLog func() error) ([]string, error) {
+	if err := validateExtraKinds(params); err != nil {
+		return nil, err
+	}
+
+	rootOutputPath := filepath.Join(path, BundleDirName(params))
+	report := newCollectionReport(params, time.Now())
+
+	collectCtx := ctx
+
+	if params.ContextTimeout > 0 {
+		var cancel context.CancelFunc
+
+		collectCtx, cancel = context.WithTimeout(ctx, params.ContextTimeout)
+		defer cancel()
+	}
+
+	// units are not started once the collection times out, objects collected till then are archived
+	prog, err := newProgress(collectCtx, params.Logger, rootOutputPath, params.Resume)
+	if err != nil {
+		return nil, err
+	}
+
+	state := newCollectionState(params, report.StartTime)
+
+	var baseline map[string]baselineObject
+
+	if params.Baseline != "" {
+		if baseline, err = loadBaseline(params.Baseline); err != nil {
+			return nil, err
 		}
+	}
 
-		for _, gvk := range gvkListNSScoped {
-			if gvk.Kind == internal.PodKind {
-				if err := capturePodLogs(ctx, params.Logger, params.ClientSet, ns, objOutputDir); err != nil {
-					return err
-				}
-			} else {
-				if err := captureObject(params.Logger, params.K8sClient, gvk, ns, objOutputDir); err != nil {
-					return err
-				}
-			}
+	var truncatedErr error
+
+	if err := collect(collectCtx, params, state, prog, report, rootOutputPath); err != nil || collectCtx.Err() != nil {
+		if collectCtx.Err() == nil {
+			return nil, err
 		}
 
-		if err := captureSummary(params.Logger, ns, objOutputDir); err != nil {
-			return err
+		// collected objects are still archived, so that the time spent so far is not lost
+		truncatedErr = fmt.Errorf("collection interrupted, archived the partial bundle: %w", collectCtx.Err())
+		params.Logger.Warn("Collection interrupted, archiving the partial bundle", zap.Error(err))
+
+		if err := writeTruncatedMarker(rootOutputPath, collectCtx.Err()); err != nil {
+			return nil, err
 		}
+
+		report.Truncated = true
 	}
 
-	if params.ClusterScope {
-		params.Logger.Info("Capturing cluster scoped objects info")
+	var strictErr error
 
-		objOutputDir := filepath.Join(rootOutputPath, ClusterScopedDir)
-		if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
-			return err
-		}
+	// partial bundles are not checked, as the expected kinds may not be listed yet
+	if params.Strict && truncatedErr == nil {
+		strictErr = checkStrict(params, state, report)
+	}
 
-		for _, gvk := range gvkListClusterScoped {
-			if err := captureObject(params.Logger, params.K8sClient, gvk, "", objOutputDir); err != nil {
-				return err
-			}
+	if err := writeOwnershipGraph(state, rootOutputPath); err != nil {
+		return nil, err
+	}
+
+	// dependents of the objects being deleted are taken from the ownership graph
+	if err := state.stuckDeletions.write(state.ownerGraph, rootOutputPath); err != nil {
+		return nil, err
+	}
+
+	if baseline != nil {
+		if report.DeltaObjects, err = writeDelta(params, baseline, rootOutputPath); err != nil {
+			return nil, err
 		}
+	}
 
-		if err := captureSummary(params.Logger, "", objOutputDir); err != nil {
-			return err
+	if params.SingleJSON {
+		if err := state.snapshot.write(rootOutputPath); err != nil {
+			return nil, err
 		}
 	}
 
-	params.Logger.Info("Compressing and deleting all logs and created ", zap.String("tar file", TarName))
+	report.Failures = state.failures.list()
 
-	return makeTarAndClean(path)
-}
+	if err := report.write(rootOutputPath); err != nil {
+		return nil, err
+	}
 
-func captureObject(logger *zap.Logger, k8sClient client.Client, gvk schema.GroupVersionKind,
-	ns, rootOutputPath string) error {
-	listOps := &client.ListOptions{Namespace: ns}
-	u := &unstructured.UnstructuredList{}
+	if err := prog.clean(); err != nil {
+		return nil, err
+	}
 
-	u.SetGroupVersionKind(gvk)
+	if w == nil {
+		params.Logger.Info("Compressing and deleting all logs and created ",
+			zap.String("tar file", BundleTarName(params)))
+	} else {
+		params.Logger.Info("Compressing and deleting all logs and created files into the output stream")
+	}
 
-	if err := k8sClient.List(context.TODO(), u, listOps); err != nil {
-		if gvk.Kind == internal.AerospikeClusterKind && errors.Is(err, &meta.NoKindMatchError{}) {
-			gvk.Version = "v1beta1"
-			u.SetGroupVersionKind(gvk)
+	if detachLog != nil {
+		if err := detachLog(); err != nil {
+			return nil, err
+		}
+	}
 
-			if listErr := k8sClient.List(context.TODO(), u, listOps); listErr != nil {
-				logger.Error("Not able to list ",
-					zap.String("kind", gvk.Kind), zap.String("version", gvk.Version), zap.Error(listErr))
-				return err
-			}
-		} else {
-			logger.Error("Not able to list ", zap.String("kind", gvk.Kind), zap.Error(err))
-			return err
+	archives, err := makeTarAndClean(params, path, w)
+	if err != nil {
+		return nil, err
+	}
+
+	// post command is still run for a partial bundle, unless the collection itself is cancelled
+	if len(params.PostCommand) == 0 || ctx.Err() != nil {
+		return archives, errors.Join(truncatedErr, strictErr)
+	}
+
+	for _, archive := range archives {
+		if err := runPostCommand(ctx, params, archive); err != nil {
+			return archives, err
 		}
 	}
 
-	if len(u.Items) == 0 {
-		logger.Info("No resource found in namespace", zap.String("kind", gvk.Kind),
-			zap.String("namespace", ns))
-		return nil
+	return archives, errors.Join(truncatedErr, strictErr)
+}
+
+// collectionState is the state recorded by a collection, which selects the related objects and builds the
+// summaries written after the objects. Each collection starts with a new state, so that collections can be run
+// repeatedly in the same process.
+type collectionState struct {
+	ownerGraph *ownershipGraph
+	// pvcNameSet has the volumes of collected PVCs, used to select PVs and VolumeAttachments
+	pvcNameSet sets.Set[string]
+	// storageClassNameSet has the storage classes of collected PVCs, used to select storage classes in cluster mode
+	storageClassNameSet sets.Set[string]
+	// aerospikeNodeNameSet has the nodes hosting the collected Aerospike pods, used to select nodes
+	aerospikeNodeNameSet sets.Set[string]
+	// priorityClassNameSet has the PriorityClasses referenced by collected pods, used to select PriorityClasses
+	priorityClassNameSet sets.Set[string]
+	// skippedKinds are the kinds not served by the cluster as per discovery, which are not collected
+	skippedKinds sets.Set[string]
+	// redactionRules are the parsed paths to be redacted of each kind
+	redactionRules map[string][][]pathSegment
+	// changedSinceTime is the time since which objects must be created or modified to be collected, all objects
+	// are collected if it is zero
+	changedSinceTime time.Time
+	rollouts         *rolloutState
+	failures         *failureRecorder
+	storage          *storageState
+	nodeResources    *nodeResourceState
+	snapshot         *clusterSnapshot
+	foundKinds       *kindCounter
+	stuckDeletions   *deletionRecorder
+	changeHistories  *changeHistoryState
+	pdbs             *pdbState
+	crossRefs        *crossRefState
+	certExpiries     *certExpiryState
+	// selectionMutex guards insertions in the selection sets, as kinds and namespaces are collected concurrently
+	selectionMutex sync.Mutex
+}
+
+func newCollectionState(params *configuration.Parameters, startTime time.Time) *collectionState {
+	state := &collectionState{
+		ownerGraph:           newOwnershipGraph(),
+		pvcNameSet:           sets.Set[string]{},
+		storageClassNameSet:  sets.Set[string]{},
+		aerospikeNodeNameSet: sets.Set[string]{},
+		priorityClassNameSet: sets.Set[string]{},
+		skippedKinds:         sets.Set[string]{},
+		redactionRules:       parseRedactionRules(params),
+		rollouts:             newRolloutState(),
+		failures:             &failureRecorder{},
+		storage:              newStorageState(),
+		nodeResources:        newNodeResourceState(),
+		snapshot:             newClusterSnapshot(),
+		foundKinds:           newKindCounter(),
+		stuckDeletions:       newDeletionRecorder(),
+		changeHistories:      newChangeHistoryState(),
+		pdbs:                 newPDBState(),
+		crossRefs:            newCrossRefState(),
+		certExpiries:         newCertExpiryState(),
 	}
 
-	objOutputDir := filepath.Join(rootOutputPath, KindDirNames[gvk.Kind])
-	if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
+	if params.ChangedSince > 0 {
+		state.changedSinceTime = startTime.Add(-params.ChangedSince)
+	}
+
+	return state
+}
+
+// collect captures the objects, logs and summaries of the collection in rootOutputPath.
+func collect(ctx context.Context, params *configuration.Parameters, state *collectionState, prog *progress,
+	report *CollectionReport, rootOutputPath string) error {
+	if err := prog.run("cluster/"+ClusterInfoFile, func() error {
+		return CaptureClusterInfo(params.Logger, params.ClientSet.Discovery(),
+			filepath.Join(rootOutputPath, ClusterScopedDir))
+	}); err != nil {
 		return err
 	}
 
-	count := 0
+	checkOperatorCRDs(params, state, report)
+	checkDiscovery(params, state, report)
 
-	for idx := range u.Items {
-		switch gvk.Kind {
-		case internal.PVCKind:
-			obj := u.Items[idx].Object
-			if obj["spec"].(map[string]interface{})["volumeName"] != nil {
-				volumeName := obj["spec"].(map[string]interface{})["volumeName"].(string)
-				pvcNameSet.Insert(volumeName)
-			}
-		case internal.PVKind:
-			if !pvcNameSet.Has(u.Items[idx].GetName()) {
-				continue
+	if params.NamespaceScoped() {
+		if err := captureNamespaceScoped(ctx, params, state, prog, rootOutputPath); err != nil {
+			return err
+		}
+	} else if params.ClusterScoped() {
+		// PVCs and pods are not collected, but still listed to select PVs, nodes and PriorityClasses
+		for ns := range params.Namespaces {
+			if err := listPVCVolumeNames(ctx, params, state, ns); err != nil {
+				return err
 			}
-		case internal.ValidatingWebhookKind:
-			name := u.Items[idx].GetName()
-			if !(strings.HasPrefix(name, ValidatingWebhookPrefix) || name == ValidatingWebhookName) {
-				continue
+
+			if err := listPriorityClassNames(ctx, params, state, ns); err != nil {
+				return err
 			}
-		case internal.MutatingWebhookKind:
-			name := u.Items[idx].GetName()
-			if !(strings.HasPrefix(name, MutatingWebhookPrefix) || name == MutatingWebhookName) {
-				continue
+
+			if params.AerospikeNodes {
+				if err := listAerospikeNodeNames(ctx, params, state, ns); err != nil {
+					return err
+				}
 			}
 		}
+	}
 
-		if err := serializeAndWrite(u.Items[idx], objOutputDir); err != nil {
+	if params.ClusterScoped() {
+		if err := captureClusterScoped(ctx, params, state, prog, report, rootOutputPath); err != nil {
 			return err
 		}
 
-		count++
+		if err := state.nodeResources.writeAllocatable(filepath.Join(rootOutputPath, ClusterScopedDir)); err != nil {
+			return err
+		}
+
+		// PVCs are correlated with the PVs and StorageClasses collected above
+		if err := state.storage.writeSummary(rootOutputPath); err != nil {
+			return err
+		}
 	}
 
-	logger.Info("Successfully saved ", zap.String("kind", gvk.Kind),
-		zap.Int("number of objects", count), zap.String("namespace", ns))
+	if params.PrometheusURL != "" {
+		if err := prog.run(MetricsDir, func() error {
+			return captureMetrics(ctx, params, state, rootOutputPath)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if params.TargetNode != "" {
+		if err := prog.run("cluster/"+KindDirNames[internal.NodeKind]+"/"+params.TargetNode, func() error {
+			return captureTargetNode(ctx, params, state, rootOutputPath)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if params.Autoscaler {
+		if err := prog.run("cluster/"+AutoscalerDir, func() error {
+			return captureAutoscaler(ctx, params, state, rootOutputPath)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if params.LogSelector != "" {
+		if err := prog.run(CrossNamespaceLogsDir, func() error {
+			return captureCrossNamespaceLogs(ctx, params, state, rootOutputPath)
+		}); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-func captureSummary(logger *zap.Logger, ns, rootOutputPath string) error {
-	_, err := exec.LookPath(kubectlCMD)
+// writeTruncatedMarker writes TruncatedFile in the bundle, telling that the collection was interrupted by the
+// given error and the bundle is partial.
+func writeTruncatedMarker(rootOutputPath string, cause error) error {
+	if err := os.MkdirAll(rootOutputPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	marker := fmt.Sprintf("Collection interrupted at %s: %v\nThe bundle has only the data collected till then. "+
+		"See %s for the units which failed.\n", time.Now().Format(time.RFC3339), cause, ReportFile)
+
+	return populateScraperDir([]byte(marker), filepath.Join(rootOutputPath, TruncatedFile))
+}
+
+// runPostCommand runs the post command with the archive path, replacing ArchivePathPlaceholder if present in
+// the command, otherwise appending it as the last argument. Output of the command is logged.
+func runPostCommand(ctx context.Context, params *configuration.Parameters, archivePath string) error {
+	archivePath, err := filepath.Abs(archivePath)
 	if err != nil {
-		logger.Error("not able to collect cluster summary", zap.Error(err))
-		return nil
+		return err
 	}
 
-	cmdMap := make(map[string]*exec.Cmd)
+	args := make([]string, 0, len(params.PostCommand))
+	replaced := false
 
-	if ns != "" {
-		for _, gvk := range gvkListNSScoped {
-			cmd := exec.Command(kubectlCMD, "get", gvk.Kind, "-n", ns) //nolint:gosec // kind is constant
-			cmdMap[gvk.Kind] = cmd
+	for _, arg := range params.PostCommand[1:] {
+		if arg == ArchivePathPlaceholder {
+			arg = archivePath
+			replaced = true
 		}
 
-		//nolint:gosec // kind is constant
-		cmd := exec.Command(kubectlCMD, "get", internal.EventKind, "-n", ns, "--sort-by=.metadata.creationTimestamp")
-		cmdMap[internal.EventKind] = cmd
-	} else {
-		for _, gvk := range gvkListClusterScoped {
-			cmd := exec.Command(kubectlCMD, "get", gvk.Kind) //nolint:gosec // kind is constant
-			cmdMap[gvk.Kind] = cmd
-		}
+		args = append(args, arg)
 	}
 
-	var (
-		finalSummary []byte
-		events       []byte
-	)
+	if !replaced {
+		args = append(args, archivePath)
+	}
 
-	for kind, cmd := range cmdMap {
-		divider := fmt.Sprintf("\n%s\n%s%s\n%s\n",
-			strings.Repeat("-", 100), strings.Repeat(" ", 50-len(kind)/2), kind, strings.Repeat("-", 100))
+	params.Logger.Info("Running post command", zap.String("command", params.PostCommand[0]),
+		zap.Strings("args", args))
 
-		out, err := cmd.Output()
-		if err != nil {
-			logger.Error("could not run command: ", zap.Error(err))
-			continue
+	// command is given by the user and validated, it is run without a shell
+	output, err := exec.CommandContext(ctx, params.PostCommand[0], args...).CombinedOutput() //nolint:gosec // see above
+	params.Logger.Info("Post command output", zap.String("output", string(output)))
+
+	if err != nil {
+		return fmt.Errorf("post command failed: %v", err)
+	}
+
+	return nil
+}
+
+func captureNamespaceScoped(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	prog *progress, rootOutputPath string) error {
+	params.Logger.Info("Capturing namespace scoped objects info")
+
+	for ns := range params.Namespaces {
+		objOutputDir := filepath.Join(rootOutputPath, NamespaceScopedDir, ns)
+		if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
+			return err
 		}
 
-		switch kind {
-		case internal.PVKind:
-			out = filterPersistentVolumes(out)
-		case internal.MutatingWebhookKind:
-			out = filterWebhooks(out)
-		case internal.ValidatingWebhookKind:
-			out = filterWebhooks(out)
-		case internal.EventKind:
-			events = out
-			continue
+		recordNamespace(ctx, params, state, ns)
+
+		if err := captureNamespaceKinds(ctx, params, state, prog, ns, objOutputDir); err != nil {
+			return err
+		}
+
+		// diff is built from the controllers and pods collected above
+		if err := prog.run(ns+"/"+RolloutDiffFile, func() error {
+			return state.rollouts.writeDiffs(ns, objOutputDir)
+		}); err != nil {
+			return err
+		}
+
+		// status is correlated with the budgets and pods collected above
+		if err := prog.run(ns+"/"+PDBStatusFile, func() error {
+			return state.pdbs.write(ns, objOutputDir)
+		}); err != nil {
+			return err
+		}
+
+		if err := prog.run(ns+"/"+AdmissionFailuresFile, func() error {
+			return captureAdmissionFailures(ctx, params, state, ns, objOutputDir)
+		}); err != nil {
+			return err
+		}
+
+		if err := prog.run(ns+"/"+internal.ConfigMapKind, func() error {
+			return captureObject(ctx, params, state, corev1.SchemeGroupVersion.WithKind(internal.ConfigMapKind), ns,
+				objOutputDir)
+		}); err != nil {
+			return err
+		}
+
+		// operator is not owned by a cluster
+		if params.ClusterName == "" {
+			if err := prog.run(ns+"/"+internal.ServiceAccountKind, func() error {
+				return captureObject(ctx, params, state, corev1.SchemeGroupVersion.WithKind(internal.ServiceAccountKind), ns,
+					objOutputDir)
+			}); err != nil {
+				return err
+			}
+		}
+
+		// backup services are not owned by a cluster
+		if params.ClusterName == "" && !state.isKindSkipped(internal.BackupServiceKind) {
+			if err := prog.run(ns+"/"+internal.BackupServiceKind, func() error {
+				return captureBackupServices(ctx, params, state, ns, objOutputDir)
+			}); err != nil {
+				return err
+			}
+		}
+
+		// histories are recorded while collecting the Aerospike CRs above
+		if err := prog.run(ns+"/"+ChangeHistoryFile, func() error {
+			return state.changeHistories.write(ctx, params, ns, objOutputDir)
+		}); err != nil {
+			return err
+		}
+
+		// references are recorded while collecting the Aerospike CRs above
+		if err := prog.run(ns+"/"+CrossNamespaceRefsFile, func() error {
+			return state.crossRefs.write(ctx, params, state, ns, objOutputDir)
+		}); err != nil {
+			return err
+		}
+
+		if err := prog.run(ns+"/"+CertExpiryFile, func() error {
+			return state.certExpiries.write(ctx, params, state.failures, ns, objOutputDir)
+		}); err != nil {
+			return err
+		}
+
+		if err := prog.run(ns+"/"+SummaryDir, func() error {
+			return captureSummary(ctx, params, state, ns, objOutputDir)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if params.IncludeKubeSystem && !params.Namespaces.Has(KubeSystemNamespace) {
+		return prog.run(KubeSystemNamespace+"/"+internal.PodKind, func() error {
+			return captureKubeSystemPods(ctx, params, state, rootOutputPath)
+		})
+	}
+
+	return nil
+}
+
+// isOperatorConfigMap returns true if the given ConfigMap name belongs to the operator.
+func isOperatorConfigMap(name string) bool {
+	for _, prefix := range OperatorConfigMapPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// captureNamespaceKinds collects the kinds of the given namespace concurrently, each in its own kind directory.
+// Kinds selected using their owners are collected after all the other kinds.
+func captureNamespaceKinds(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	prog *progress, ns, objOutputDir string) error {
+	var wg sync.WaitGroup
+
+	gvkList := nsScopedGVKs(params)
+	errCh := make(chan error, len(gvkList))
+	sem := make(chan struct{}, maxConcurrentKinds)
+
+	for _, gvk := range gvkList {
+		if isOwnerSelected(params, gvk.Kind) {
+			continue
+		}
+
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := captureNamespaceKind(ctx, params, state, prog, gvk, ns, objOutputDir); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	if err := <-errCh; err != nil {
+		return err
+	}
+
+	for _, gvk := range gvkList {
+		if !isOwnerSelected(params, gvk.Kind) {
+			continue
+		}
+
+		if err := captureNamespaceKind(ctx, params, state, prog, gvk, ns, objOutputDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func captureNamespaceKind(ctx context.Context, params *configuration.Parameters, state *collectionState, prog *progress,
+	gvk schema.GroupVersionKind, ns, objOutputDir string) error {
+	if state.isKindSkipped(gvk.Kind) {
+		return nil
+	}
+
+	// PVCs are always listed, as PV selection depends on them
+	if gvk.Kind == internal.PVCKind {
+		return captureObject(ctx, params, state, gvk, ns, objOutputDir)
+	}
+
+	// objects collected by the previous run still select the objects of the kinds collected after them
+	if prog.isCompleted(ns + "/" + gvk.Kind) {
+		return recordResumedObjects(ctx, params, state, gvk, ns)
+	}
+
+	return prog.run(ns+"/"+gvk.Kind, func() error {
+		if gvk.Kind == internal.PodKind {
+			return capturePodLogs(ctx, params, state, ns, objOutputDir, metav1.ListOptions{},
+				newLogBudget(params.MaxNamespaceLogBytes))
+		}
+
+		return captureObject(ctx, params, state, gvk, ns, objOutputDir)
+	})
+}
+
+func captureClusterScoped(ctx context.Context, params *configuration.Parameters, state *collectionState, prog *progress,
+	report *CollectionReport, rootOutputPath string) error {
+	params.Logger.Info("Capturing cluster scoped objects info")
+
+	objOutputDir := filepath.Join(rootOutputPath, ClusterScopedDir)
+	if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	for _, gvk := range clusterScopedGVKs(params) {
+		if params.ClusterName != "" && !clusterModeClusterKinds.Has(gvk.Kind) {
+			continue
+		}
+
+		if state.isKindSkipped(gvk.Kind) {
+			continue
+		}
+
+		// PVs are always listed, as they are selected using PVCs listed in this run
+		if gvk.Kind == internal.PVKind {
+			if err := captureClusterKind(ctx, params, state, report, gvk, objOutputDir); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := prog.run("cluster/"+gvk.Kind, func() error {
+			return captureClusterKind(ctx, params, state, report, gvk, objOutputDir)
+		}); err != nil {
+			return err
+		}
+	}
+
+	// health and summary are of the whole kubernetes cluster
+	if params.ClusterName != "" {
+		return nil
+	}
+
+	if err := prog.run("cluster/"+WebhookHealthFile, func() error {
+		return captureWebhookHealth(ctx, params, objOutputDir)
+	}); err != nil {
+		return err
+	}
+
+	if err := prog.run("cluster/"+HealthDir, func() error {
+		return captureHealth(ctx, params, objOutputDir)
+	}); err != nil {
+		return err
+	}
+
+	return prog.run("cluster/"+SummaryDir, func() error {
+		return captureSummary(ctx, params, state, "", objOutputDir)
+	})
+}
+
+// captureClusterKind captures objects of the given cluster scoped kind.
+// Kinds which the user is forbidden to list are skipped with a warning and recorded in the report.
+func captureClusterKind(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	report *CollectionReport, gvk schema.GroupVersionKind, objOutputDir string) error {
+	err := captureObject(ctx, params, state, gvk, "", objOutputDir)
+	if apierrors.IsForbidden(err) {
+		params.Logger.Warn("Not allowed to list cluster scoped kind, skipping", zap.String("kind", gvk.Kind),
+			zap.Error(err))
+		report.skipKind(gvk.Kind, err)
+
+		return nil
+	}
+
+	return err
+}
+
+// listPVCVolumeNames adds volume names of PVCs in the given namespace to the PV selection set.
+func listPVCVolumeNames(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	ns string) error {
+	pvcs, err := params.ClientSet.CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		params.Logger.Error("Not able to list ", zap.String("kind", internal.PVCKind), zap.Error(err))
+		return err
+	}
+
+	for idx := range pvcs.Items {
+		if params.ClusterName != "" && pvcs.Items[idx].Labels[ClusterNameLabel] != params.ClusterName {
+			continue
+		}
+
+		if pvcs.Items[idx].Spec.VolumeName != "" {
+			state.selectionMutex.Lock()
+			state.pvcNameSet.Insert(pvcs.Items[idx].Spec.VolumeName)
+			state.selectionMutex.Unlock()
+		}
+	}
+
+	return nil
+}
+
+// recordPVC adds the volume and the storage class of the given PVC to the PV and storage class selection sets.
+func recordPVC(state *collectionState, pvc *unstructured.Unstructured) {
+	volumeName, _, _ := unstructured.NestedString(pvc.Object, "spec", "volumeName")
+	storageClassName, hasStorageClass, _ := unstructured.NestedString(pvc.Object, "spec", "storageClassName")
+
+	state.selectionMutex.Lock()
+	defer state.selectionMutex.Unlock()
+
+	if volumeName != "" {
+		state.pvcNameSet.Insert(volumeName)
+	}
+
+	if hasStorageClass {
+		state.storageClassNameSet.Insert(storageClassName)
+	}
+}
+
+func captureObject(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	gvk schema.GroupVersionKind, ns, rootOutputPath string) error {
+	return collectObjects(ctx, params, state, gvk, ns, rootOutputPath, true)
+}
+
+// collectObjects lists the objects of the given kind page by page and records the selected ones, which select the
+// related objects and build the summaries. The selected objects are written in rootOutputPath if write is set.
+func collectObjects(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	gvk schema.GroupVersionKind, ns, rootOutputPath string, write bool) error {
+	logger := params.Logger
+	listOps := &client.ListOptions{Namespace: ns}
+	u := &unstructured.UnstructuredList{}
+
+	if params.ResourceVersion != "" {
+		listOps.Raw = &metav1.ListOptions{}
+		setResourceVersion(params, listOps.Raw)
+	}
+
+	if params.PageSize > 0 {
+		listOps.Limit = params.PageSize
+	}
+
+	if gvk.Kind == internal.NodeKind && params.NodeSelector != "" {
+		// selector is validated while setting it
+		listOps.LabelSelector, _ = labels.Parse(params.NodeSelector)
+	}
+
+	u.SetGroupVersionKind(gvk)
+
+	if err := listObjects(ctx, params, u, listOps); err != nil {
+		if gvk.Kind == internal.AerospikeClusterKind && errors.Is(err, &meta.NoKindMatchError{}) {
+			gvk.Version = "v1beta1"
+			u.SetGroupVersionKind(gvk)
+
+			if listErr := listObjects(ctx, params, u, listOps); listErr != nil {
+				logger.Error("Not able to list ",
+					zap.String("kind", gvk.Kind), zap.String("version", gvk.Version), zap.Error(listErr))
+				return err
+			}
+		} else if optionalKinds.Has(gvk.Kind) && meta.IsNoMatchError(err) {
+			logger.Info("Kind not served by the cluster, skipping", zap.String("kind", gvk.Kind))
+			return nil
+		} else {
+			logger.Error("Not able to list ", zap.String("kind", gvk.Kind), zap.Error(err))
+			return err
+		}
+	}
+
+	if len(u.Items) == 0 && u.GetContinue() == "" {
+		logger.Info("No resource found in namespace", zap.String("kind", gvk.Kind),
+			zap.String("namespace", ns))
+		return nil
+	}
+
+	objOutputDir := filepath.Join(rootOutputPath, kindDir(gvk.Kind))
+
+	switch {
+	case !write:
+		// objects are only recorded
+	case params.SingleJSON:
+		// objects are written in SnapshotFile at the end of the collection
+	case params.Consolidate:
+		// remove objects appended by an interrupted run
+		if err := os.Remove(objOutputDir + JSONLinesSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	default:
+		if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	count := 0
+
+	for {
+		state.foundKinds.add(gvk.Kind, len(u.Items))
+
+		for idx := range u.Items {
+			if params.ClusterName != "" && ns != "" && !isClusterObject(params, state, gvk.Kind, &u.Items[idx]) {
+				continue
+			}
+
+			// PVs and storage classes of all the PVCs are selected, even of the ones filtered out by name prefix
+			if gvk.Kind == internal.PVCKind {
+				recordPVC(state, &u.Items[idx])
+			}
+
+			// Operator ConfigMaps and objects are not filtered by name prefix, so that the operator is never missing
+			operatorConfigMap := gvk.Kind == internal.ConfigMapKind && isOperatorConfigMap(u.Items[idx].GetName())
+			if !operatorConfigMap && !isOperatorObject(params, gvk.Kind, &u.Items[idx]) &&
+				!strings.HasPrefix(u.Items[idx].GetName(), params.NamePrefix) {
+				continue
+			}
+
+			switch gvk.Kind {
+			case internal.PVKind:
+				if !state.pvcNameSet.Has(u.Items[idx].GetName()) {
+					continue
+				}
+			case internal.PodKind:
+				priorityClassName, _, _ := unstructured.NestedString(u.Items[idx].Object, "spec", "priorityClassName")
+				recordPriorityClass(state, priorityClassName)
+			case internal.PriorityClassKind:
+				if !state.priorityClassNameSet.Has(u.Items[idx].GetName()) {
+					continue
+				}
+			case internal.NodeKind:
+				if !isSelectedNode(params, state, &u.Items[idx]) {
+					continue
+				}
+			case internal.VolumeAttachmentKind:
+				pvName, _, _ := unstructured.NestedString(u.Items[idx].Object, "spec", "source", "persistentVolumeName")
+				if !state.pvcNameSet.Has(pvName) {
+					continue
+				}
+			case internal.SCKind:
+				if params.ClusterName != "" && !state.storageClassNameSet.Has(u.Items[idx].GetName()) {
+					continue
+				}
+			case internal.ControllerRevisionKind:
+				if !state.ownerGraph.hasCollectedOwner(&u.Items[idx]) {
+					continue
+				}
+			case internal.ConfigMapKind:
+				// in cluster mode, ConfigMaps of the cluster are already selected
+				if params.ClusterName == "" && !isOperatorConfigMap(u.Items[idx].GetName()) {
+					continue
+				}
+			case internal.ServiceAccountKind:
+				if !isOperatorObject(params, gvk.Kind, &u.Items[idx]) {
+					continue
+				}
+			case internal.ValidatingWebhookKind, internal.MutatingWebhookKind:
+				if !isOperatorWebhookConfig(gvk.Kind, u.Items[idx].GetName()) {
+					continue
+				}
+			default:
+				if !strings.HasPrefix(u.Items[idx].GetName(), extraKindNamePrefix(params, gvk.Kind)) {
+					continue
+				}
+			}
+
+			// objects are still recorded above to select the related objects
+			if !isChangedSince(state, &u.Items[idx]) {
+				continue
+			}
+
+			if gvk.Kind == internal.STSKind || gvk.Kind == internal.DeployKind {
+				if err := state.rollouts.recordController(gvk.Kind, &u.Items[idx]); err != nil {
+					logger.Warn("Not able to record pod template, skipping rollout diff", zap.String("kind", gvk.Kind),
+						zap.String("name", u.Items[idx].GetName()), zap.Error(err))
+				}
+			}
+
+			if gvk.Kind == internal.PDBKind {
+				if err := state.pdbs.recordBudget(&u.Items[idx]); err != nil {
+					logger.Warn("Not able to record PodDisruptionBudget, skipping it in PDB status",
+						zap.String("name", u.Items[idx].GetName()), zap.Error(err))
+				}
+			}
+
+			if gvk.Kind == internal.NodeKind {
+				if err := state.nodeResources.recordNode(&u.Items[idx]); err != nil {
+					logger.Warn("Not able to record node, skipping it in node allocatable",
+						zap.String("name", u.Items[idx].GetName()), zap.Error(err))
+				}
+			}
+
+			if err := state.storage.record(gvk.Kind, &u.Items[idx]); err != nil {
+				logger.Warn("Not able to record storage object, skipping it in storage summary",
+					zap.String("kind", gvk.Kind), zap.String("name", u.Items[idx].GetName()), zap.Error(err))
+			}
+
+			// managers are recorded before managed fields are stripped
+			if gvk.Group == OperatorGroup {
+				state.changeHistories.record(gvk.Kind, &u.Items[idx])
+				state.crossRefs.record(gvk.Kind, &u.Items[idx])
+				state.certExpiries.record(gvk.Kind, &u.Items[idx])
+			}
+
+			if write {
+				stripManagedFields(params, &u.Items[idx])
+
+				if err := writeObject(params, state, gvk.Kind, &u.Items[idx], objOutputDir); err != nil {
+					state.failures.record(logger, gvk.Kind, ns, u.Items[idx].GetName(), err)
+					continue
+				}
+			}
+
+			state.ownerGraph.record(gvk.Kind, &u.Items[idx])
+			state.stuckDeletions.record(gvk.Kind, &u.Items[idx])
+
+			count++
+		}
+
+		if u.GetContinue() == "" {
+			break
+		}
+
+		next, err := listNextPage(ctx, params, gvk, u.GetContinue(), listOps)
+		if err != nil {
+			logger.Error("Not able to list next page of ", zap.String("kind", gvk.Kind), zap.Error(err))
+			return err
+		}
+
+		u = next
+	}
+
+	if !write {
+		logger.Info("Successfully recorded ", zap.String("kind", gvk.Kind),
+			zap.Int("number of objects", count), zap.String("namespace", ns))
+
+		return nil
+	}
+
+	logger.Info("Successfully saved ", zap.String("kind", gvk.Kind),
+		zap.Int("number of objects", count), zap.String("namespace", ns))
+
+	return nil
+}
+
+// writeObject writes the given object in objOutputDir, appends it to the consolidated file of its kind, or adds it to
+// the snapshot, as per params.
+func writeObject(params *configuration.Parameters, state *collectionState, kind string,
+	obj *unstructured.Unstructured, objOutputDir string) error {
+	switch {
+	case params.SingleJSON:
+		redactObject(state, kind, obj.Object)
+		state.snapshot.add(kind, obj.Object)
+
+		return nil
+	case params.Consolidate:
+		redactObject(state, kind, obj.Object)
+
+		return serializeAndAppend(obj.Object, objOutputDir+JSONLinesSuffix)
+	default:
+		return serializeAndWrite(params, state, *obj, objOutputDir)
+	}
+}
+
+// listObjects lists the objects using the given options. If the resource version given in the options is too old,
+// the latest objects are listed.
+func listObjects(ctx context.Context, params *configuration.Parameters, u *unstructured.UnstructuredList,
+	listOps *client.ListOptions) error {
+	err := params.K8sClient.List(ctx, u, listOps)
+	if listOps.Raw == nil || !isResourceVersionTooOld(params, err) {
+		return err
+	}
+
+	params.Logger.Warn("Resource version is too old, listing latest objects", zap.String("kind", u.GetKind()),
+		zap.String("resourceVersion", params.ResourceVersion), zap.Error(err))
+
+	listOps.Raw.ResourceVersion, listOps.Raw.ResourceVersionMatch = "", ""
+
+	return params.K8sClient.List(ctx, u, listOps)
+}
+
+// listNextPage lists the next page of objects of the given kind, as per the continue token of the previous page.
+// Options of the first page are copied without the resource version, which can not be given along with a continue
+// token, as the token keeps the resource version of the first page.
+func listNextPage(ctx context.Context, params *configuration.Parameters, gvk schema.GroupVersionKind,
+	continueToken string, listOps *client.ListOptions) (*unstructured.UnstructuredList, error) {
+	next := &unstructured.UnstructuredList{}
+	next.SetGroupVersionKind(gvk)
+
+	pageOps := &client.ListOptions{
+		Namespace:     listOps.Namespace,
+		LabelSelector: listOps.LabelSelector,
+		FieldSelector: listOps.FieldSelector,
+		Limit:         listOps.Limit,
+		Continue:      continueToken,
+	}
+
+	return next, params.K8sClient.List(ctx, next, pageOps)
+}
+
+// setResourceVersion sets the resource version given in params in the list options, so that objects are listed
+// from the same snapshot.
+func setResourceVersion(params *configuration.Parameters, opts *metav1.ListOptions) {
+	if params.ResourceVersion == "" {
+		return
+	}
+
+	opts.ResourceVersion = params.ResourceVersion
+	opts.ResourceVersionMatch = metav1.ResourceVersionMatchExact
+}
+
+// isResourceVersionTooOld returns true if the error is because the resource version given in params is compacted.
+func isResourceVersionTooOld(params *configuration.Parameters, err error) bool {
+	return params.ResourceVersion != "" && (apierrors.IsResourceExpired(err) || apierrors.IsGone(err))
+}
+
+func captureSummary(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	ns, rootOutputPath string) error {
+	logger := params.Logger
+
+	_, err := exec.LookPath(kubectlCMD)
+	if err != nil {
+		logger.Error("not able to collect cluster summary", zap.Error(err))
+		return nil
+	}
+
+	cmdMap := make(map[string]*exec.Cmd)
+
+	if ns != "" {
+		for _, gvk := range nsScopedGVKs(params) {
+			if state.isKindSkipped(gvk.Kind) {
+				continue
+			}
+
+			args := []string{"get", gvk.Kind, "-n", ns}
+			if params.ClusterName != "" {
+				args = append(args, clusterSummaryArgs(params, gvk.Kind)...)
+			}
+
+			if selector := params.FieldSelectors[gvk.Kind]; selector != "" {
+				args = append(args, "--field-selector", selector)
+			}
+
+			cmdMap[gvk.Kind] = exec.CommandContext(ctx, kubectlCMD, args...) //nolint:gosec // validated selector
+		}
+
+		// only events of the collected objects are captured in cluster mode
+		if params.ClusterName == "" {
+			args := []string{"get", internal.EventKind, "-n", ns, "--sort-by=.metadata.creationTimestamp"}
+			if selector := params.FieldSelectors[internal.EventKind]; selector != "" {
+				args = append(args, "--field-selector", selector)
+			}
+
+			cmdMap[internal.EventKind] = exec.CommandContext(ctx, kubectlCMD, args...) //nolint:gosec // selector is validated
+		}
+	} else {
+		for _, gvk := range clusterScopedGVKs(params) {
+			if state.isKindSkipped(gvk.Kind) {
+				continue
+			}
+
+			args := []string{"get", gvk.Kind}
+
+			if gvk.Kind == internal.NodeKind {
+				nodeArgs, ok := nodeSummaryArgs(params, state)
+				if !ok {
+					continue
+				}
+
+				args = append(args, nodeArgs...)
+			}
+
+			cmd := exec.CommandContext(ctx, kubectlCMD, args...) //nolint:gosec // kind is constant, node selector is validated
+			cmdMap[gvk.Kind] = cmd
+		}
+	}
+
+	var (
+		finalSummary []byte
+		events       []byte
+	)
+
+	if ns != "" && params.ClusterName != "" {
+		events, err = clusterEvents(ctx, params, state, ns)
+		if err != nil {
+			logger.Error("could not list events: ", zap.Error(err))
+		}
+	}
+
+	for kind, cmd := range cmdMap {
+		divider := fmt.Sprintf("\n%s\n%s%s\n%s\n",
+			strings.Repeat("-", 100), strings.Repeat(" ", 50-len(kind)/2), kind, strings.Repeat("-", 100))
+
+		out, err := cmd.Output()
+		if err != nil {
+			logger.Error("could not run command: ", zap.Error(err))
+			continue
+		}
+
+		switch kind {
+		case internal.PVKind:
+			out = filterPersistentVolumes(state, out)
+		case internal.MutatingWebhookKind:
+			out = filterWebhooks(out)
+		case internal.ValidatingWebhookKind:
+			out = filterWebhooks(out)
+		case internal.EventKind:
+			events = out
+			continue
+		}
+
+		if len(out) > 0 {
+			finalSummary = append(finalSummary, []byte(divider)...)
+			finalSummary = append(finalSummary, out...)
+		}
+	}
+
+	objOutputDir := filepath.Join(rootOutputPath, SummaryDir)
+	if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := populateScraperDir(finalSummary, filepath.Join(objOutputDir, SummaryFile)); err != nil {
+		return err
+	}
+
+	if len(events) > 0 {
+		if err := populateScraperDir(events, filepath.Join(objOutputDir, EventsFile)); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Successfully saved summary", zap.String("namespace", ns))
+
+	return nil
+}
+
+func filterPersistentVolumes(state *collectionState, out []byte) (finalOut []byte) {
+	outList := bytes.Split(out, []byte("\n"))
+
+	// Inserting "NAME" string to capture headers of kubectl command output
+	state.pvcNameSet.Insert("NAME")
+
+	for _, o := range outList {
+		for pvc := range state.pvcNameSet {
+			if bytes.Contains(o, []byte(pvc)) {
+				finalOut = append(finalOut, o...)
+				finalOut = append(finalOut, []byte("\n")...)
+			}
+		}
+	}
+
+	return finalOut
+}
+
+func filterWebhooks(out []byte) (finalOut []byte) {
+	outList := bytes.Split(out, []byte("\n"))
+	webhookNameSet := sets.Set[string]{}
+
+	webhookNameSet.Insert(
+		MutatingWebhookName, MutatingWebhookPrefix, ValidatingWebhookName, ValidatingWebhookPrefix, "NAME")
+
+	for _, o := range outList {
+		for webhook := range webhookNameSet {
+			if bytes.Contains(o, []byte(webhook)) {
+				finalOut = append(finalOut, o...)
+				finalOut = append(finalOut, []byte("\n")...)
+			}
+		}
+	}
+
+	return finalOut
+}
+
+// makeTarAndClean archives the bundle directory and removes it, returning paths of the generated archives.
+// If PerNamespaceArchive is set, each namespace is archived separately, and the rest of the bundle is archived
+// as ClusterScopedDir part. If w is given, the whole bundle is archived in it and no path is returned.
+func makeTarAndClean(params *configuration.Parameters, pathToStore string, w io.Writer) ([]string, error) {
+	bundleDir := BundleDirName(params)
+
+	if params.PerFileGzip {
+		if err := gzipLargeLogs(filepath.Join(pathToStore, bundleDir), params.PerFileGzipThreshold); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeManifests(params, filepath.Join(pathToStore, bundleDir)); err != nil {
+		return nil, err
+	}
+
+	if params.FileMode != 0 {
+		if err := applyFileMode(filepath.Join(pathToStore, bundleDir), params.FileMode); err != nil {
+			return nil, err
+		}
+	}
+
+	if w != nil {
+		if err := compress(params, pathToStore, bundleDir, w); err != nil {
+			return nil, err
+		}
+
+		return nil, os.RemoveAll(filepath.Join(pathToStore, bundleDir))
+	}
+
+	if params.AppendTo != "" {
+		archive, err := appendArchive(params, pathToStore, bundleDir)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{archive}, os.RemoveAll(filepath.Join(pathToStore, bundleDir))
+	}
+
+	tarName := BundleTarName(params)
+
+	var archives []string
+
+	if params.PerNamespaceArchive {
+		nsScopedDir := filepath.Join(bundleDir, NamespaceScopedDir)
+
+		nsDirs, err := os.ReadDir(filepath.Join(pathToStore, nsScopedDir))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		for _, nsDir := range nsDirs {
+			archive, err := writeArchive(params, pathToStore, filepath.Join(nsScopedDir, nsDir.Name()),
+				BundlePartTarName(params, nsDir.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			archives = append(archives, archive)
+		}
+
+		if err := os.RemoveAll(filepath.Join(pathToStore, nsScopedDir)); err != nil {
+			return nil, err
+		}
+
+		tarName = BundlePartTarName(params, ClusterScopedDir)
+	}
+
+	archive, err := writeArchive(params, pathToStore, bundleDir, tarName)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(archives, archive), os.RemoveAll(filepath.Join(pathToStore, bundleDir))
+}
+
+// writeArchive streams the compressed tar of the given directory, relative to pathToStore, into the given tar file
+// and returns its path.
+func writeArchive(params *configuration.Parameters, pathToStore, dir, tarName string) (string, error) {
+	archiveMode := archiveFileMode(params)
+
+	tarFile := filepath.Join(pathToStore, tarName)
+
+	fileToWrite, err := os.OpenFile(tarFile, os.O_CREATE|os.O_TRUNC|os.O_RDWR, archiveMode)
+	if err != nil {
+		return "", err
+	}
+
+	if err := compress(params, pathToStore, dir, fileToWrite); err != nil {
+		fileToWrite.Close()
+		return "", err
+	}
+
+	if err := fileToWrite.Close(); err != nil {
+		return "", err
+	}
+
+	// set mode explicitly, as mode given while creating file is masked by umask
+	return tarFile, os.Chmod(tarFile, archiveMode)
+}
+
+// archiveFileMode returns the permission of the generated tar files.
+func archiveFileMode(params *configuration.Parameters) os.FileMode {
+	if params.FileMode != 0 {
+		return params.FileMode
+	}
+
+	return 0650
+}
+
+// applyFileMode sets the given mode on all the files present in the given directory.
+func applyFileMode(dir string, mode os.FileMode) error {
+	return filepath.WalkDir(dir, func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		return os.Chmod(file, mode)
+	})
+}
+
+// captureKubeSystemPods captures DNS and CNI related pods and their logs from kube-system namespace.
+// Other kube-system pods are skipped to keep the bundle small.
+func captureKubeSystemPods(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	rootOutputPath string) error {
+	params.Logger.Info("Capturing DNS and CNI pods info", zap.String("namespace", KubeSystemNamespace))
+
+	objOutputDir := filepath.Join(rootOutputPath, NamespaceScopedDir, KubeSystemNamespace)
+	if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	budget := newLogBudget(params.MaxNamespaceLogBytes)
+
+	for _, selector := range KubeSystemPodSelectors {
+		if err := capturePodLogs(ctx, params, state, KubeSystemNamespace, objOutputDir,
+			metav1.ListOptions{LabelSelector: selector}, budget); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// capturePodLogs captures pods and their container logs. If budget is given, logs are captured only till the budget
+// is exhausted and the skipped containers are recorded in LogsTruncatedFile.
+func capturePodLogs(ctx context.Context, params *configuration.Parameters, state *collectionState, ns,
+	rootOutputPath string, listOpts metav1.ListOptions, budget *logBudget) error {
+	logger := params.Logger
+	clientSet := params.ClientSet
+
+	pods, err := listPods(ctx, params, ns, listOpts)
+	if err != nil {
+		return err
+	}
+
+	if len(pods.Items) == 0 {
+		logger.Info("No resource found in namespace", zap.String("kind", "Pod"),
+			zap.String("namespace", ns))
+		return nil
+	}
+
+	podEvents, err := listEventsByUID(ctx, clientSet, ns, params.FieldSelectors[internal.EventKind])
+	if err != nil {
+		state.failures.record(logger, internal.EventKind, ns, "", err)
+	}
+
+	leaseHolders, err := listLeaseHolders(ctx, clientSet, ns)
+	if err != nil {
+		logger.Warn("Not able to list leases, skipping leader markers", zap.String("namespace", ns), zap.Error(err))
+	}
+
+	count := 0
+
+	var (
+		pullErrors    []pullError
+		scheduling    []podScheduling
+		collectedPods []*corev1.Pod
+	)
+
+	for podIndex := range pods.Items {
+		if !isCollectedPod(params, state, &pods.Items[podIndex]) {
+			continue
+		}
+
+		recordPod(state, &pods.Items[podIndex])
+		collectedPods = append(collectedPods, &pods.Items[podIndex])
+
+		pullErrors = append(pullErrors, podPullErrors(&pods.Items[podIndex])...)
+
+		if details, ok := podSchedulingDetails(&pods.Items[podIndex], podEvents[pods.Items[podIndex].UID]); ok {
+			scheduling = append(scheduling, details)
+		}
+
+		podLogsDir := filepath.Join(rootOutputPath, KindDirNames[internal.PodKind], pods.Items[podIndex].Name, "logs")
+		if err := os.MkdirAll(podLogsDir, os.ModePerm); err != nil {
+			return err
+		}
+
+		stripManagedFields(params, &pods.Items[podIndex])
+
+		// logs are still captured if the pod could not be written
+		if err := writePod(params, state, &pods.Items[podIndex], rootOutputPath, podLogsDir); err != nil {
+			state.failures.record(logger, internal.PodKind, ns, pods.Items[podIndex].Name, err)
+		} else {
+			state.ownerGraph.record(internal.PodKind, &pods.Items[podIndex])
+			state.stuckDeletions.record(internal.PodKind, &pods.Items[podIndex])
+		}
+
+		if params.ExecConfig && isAerospikePod(&pods.Items[podIndex]) {
+			if err := captureExecConfig(ctx, params, state, &pods.Items[podIndex], filepath.Join(podLogsDir, "..")); err != nil {
+				return err
+			}
+		}
+
+		if params.Asinfo && isAerospikePod(&pods.Items[podIndex]) {
+			if err := captureAsinfo(ctx, params, state, &pods.Items[podIndex], filepath.Join(podLogsDir, "..")); err != nil {
+				return err
+			}
+		}
+
+		if params.OperatorMetrics && isOperatorPod(params, &pods.Items[podIndex]) {
+			if err := captureOperatorMetrics(ctx, params, state, &pods.Items[podIndex],
+				filepath.Join(podLogsDir, "..")); err != nil {
+				return err
+			}
+		}
+
+		if leases := leaseHolders[pods.Items[podIndex].Name]; len(leases) > 0 {
+			if err := populateScraperDir(formatLeases(leases), filepath.Join(podLogsDir, "..", LeaderFile)); err != nil {
+				return err
+			}
+		}
+
+		if probes := formatProbes(&pods.Items[podIndex], podEvents[pods.Items[podIndex].UID]); probes != nil {
+			if err := populateScraperDir(probes, filepath.Join(podLogsDir, "..", ProbesFile)); err != nil {
+				return err
+			}
+		}
+
+		if volumes := formatVolumes(&pods.Items[podIndex]); volumes != nil {
+			if err := populateScraperDir(volumes, filepath.Join(podLogsDir, "..", VolumesFile)); err != nil {
+				return err
+			}
+		}
+
+		if events := podEvents[pods.Items[podIndex].UID]; len(events) > 0 {
+			if err := populateScraperDir(formatEvents(events), filepath.Join(podLogsDir, "..", EventsFile)); err != nil {
+				return err
+			}
+		}
+
+		if err := captureAllContainerLogs(ctx, params, state, &pods.Items[podIndex], podLogsDir, budget); err != nil {
+			return err
 		}
 
-		if len(out) > 0 {
-			finalSummary = append(finalSummary, []byte(divider)...)
-			finalSummary = append(finalSummary, out...)
+		if err := writeInitSummary(&pods.Items[podIndex], podLogsDir); err != nil {
+			return err
 		}
-	}
 
-	objOutputDir := filepath.Join(rootOutputPath, SummaryDir)
-	if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
-		return err
+		count++
 	}
 
-	if err := populateScraperDir(finalSummary, filepath.Join(objOutputDir, SummaryFile)); err != nil {
-		return err
-	}
+	logger.Info("Successfully saved ", zap.String("kind", internal.PodKind),
+		zap.Int("number of objects", count), zap.String("namespace", ns))
 
-	if len(events) > 0 {
-		if err := populateScraperDir(events, filepath.Join(objOutputDir, EventsFile)); err != nil {
+	if len(collectedPods) > 0 {
+		if err := populateScraperDir(formatPodResources(collectedPods),
+			filepath.Join(rootOutputPath, ResourcesFile)); err != nil {
 			return err
 		}
-	}
-
-	logger.Info("Successfully saved summary", zap.String("namespace", ns))
 
-	return nil
-}
-
-func filterPersistentVolumes(out []byte) (finalOut []byte) {
-	outList := bytes.Split(out, []byte("\n"))
+		if err := populateScraperDir(formatPodNetwork(collectedPods),
+			filepath.Join(rootOutputPath, PodNetworkFile)); err != nil {
+			return err
+		}
+	}
 
-	// Inserting "NAME" string to capture headers of kubectl command output
-	pvcNameSet.Insert("NAME")
+	if len(pullErrors) > 0 {
+		logger.Warn("Found containers failing to pull image", zap.String("namespace", ns),
+			zap.Int("containers", len(pullErrors)))
 
-	for _, o := range outList {
-		for pvc := range pvcNameSet {
-			if bytes.Contains(o, []byte(pvc)) {
-				finalOut = append(finalOut, o...)
-				finalOut = append(finalOut, []byte("\n")...)
-			}
+		if err := populateScraperDir(formatPullErrors(pullErrors),
+			filepath.Join(rootOutputPath, PullErrorsFile)); err != nil {
+			return err
 		}
 	}
 
-	return finalOut
-}
+	if len(scheduling) > 0 {
+		logger.Warn("Found Pending pods", zap.String("namespace", ns), zap.Int("pods", len(scheduling)))
 
-func filterWebhooks(out []byte) (finalOut []byte) {
-	outList := bytes.Split(out, []byte("\n"))
-	webhookNameSet := sets.Set[string]{}
+		if err := populateScraperDir(formatScheduling(scheduling),
+			filepath.Join(rootOutputPath, SchedulingFile)); err != nil {
+			return err
+		}
+	}
 
-	webhookNameSet.Insert(
-		MutatingWebhookName, MutatingWebhookPrefix, ValidatingWebhookName, ValidatingWebhookPrefix, "NAME")
+	if budget != nil && len(budget.truncated) > 0 {
+		logger.Warn("Pod logs truncated as per max namespace log bytes", zap.String("namespace", ns),
+			zap.Int("truncated containers", len(budget.truncated)))
 
-	for _, o := range outList {
-		for webhook := range webhookNameSet {
-			if bytes.Contains(o, []byte(webhook)) {
-				finalOut = append(finalOut, o...)
-				finalOut = append(finalOut, []byte("\n")...)
-			}
-		}
+		return populateScraperDir(budget.marker(ns),
+			filepath.Join(rootOutputPath, KindDirNames[internal.PodKind], LogsTruncatedFile))
 	}
 
-	return finalOut
+	return nil
 }
 
-func makeTarAndClean(pathToStore string) error {
-	var buf bytes.Buffer
+// listPods lists the pods of the given namespace with the pod field selector and the resource version given in
+// params. If the resource version is too old, the latest pods are listed.
+func listPods(ctx context.Context, params *configuration.Parameters, ns string, listOpts metav1.ListOptions) (
+	*corev1.PodList, error) {
+	listOpts.FieldSelector = params.FieldSelectors[internal.PodKind]
+	setResourceVersion(params, &listOpts)
 
-	if err := compress(pathToStore, &buf); err != nil {
-		return err
+	pods, err := params.ClientSet.CoreV1().Pods(ns).List(ctx, listOpts)
+	if isResourceVersionTooOld(params, err) {
+		params.Logger.Warn("Resource version is too old, listing latest objects", zap.String("kind", internal.PodKind),
+			zap.String("resourceVersion", params.ResourceVersion), zap.Error(err))
+
+		listOpts.ResourceVersion, listOpts.ResourceVersionMatch = "", ""
+		pods, err = params.ClientSet.CoreV1().Pods(ns).List(ctx, listOpts)
 	}
 
-	// write the .tar.gzip
-	fileToWrite, err := os.OpenFile(filepath.Join(pathToStore, TarName),
-		os.O_CREATE|os.O_RDWR, 0650) //nolint:gocritic // file permission
 	if err != nil {
-		return err
+		params.Logger.Error("Not able to list ", zap.String("kind", internal.PodKind), zap.Error(err))
+		return nil, err
 	}
 
-	if _, err := io.Copy(fileToWrite, &buf); err != nil {
-		return err
+	return pods, nil
+}
+
+// isCollectedPod returns true if the given pod is selected by the name prefix and the AerospikeCluster given in
+// params. Operator pods are selected irrespective of the name prefix.
+func isCollectedPod(params *configuration.Parameters, state *collectionState, pod *corev1.Pod) bool {
+	if !isOperatorObject(params, internal.PodKind, pod) && !strings.HasPrefix(pod.Name, params.NamePrefix) {
+		return false
 	}
 
-	return os.RemoveAll(filepath.Join(pathToStore, RootOutputDir))
+	return params.ClusterName == "" || isClusterObject(params, state, internal.PodKind, pod)
 }
 
-func capturePodLogs(ctx context.Context, logger *zap.Logger, clientSet *kubernetes.Clientset, ns,
-	rootOutputPath string) error {
-	pods, err := clientSet.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		logger.Error("Not able to list ", zap.String("kind", internal.PodKind), zap.Error(err))
-		return err
+// recordPod records the given pod in the selections and summaries built from the collected pods.
+func recordPod(state *collectionState, pod *corev1.Pod) {
+	recordAerospikeNode(state, pod)
+	recordPriorityClass(state, pod.Spec.PriorityClassName)
+	state.rollouts.recordPod(pod)
+	state.pdbs.recordPod(pod)
+	state.nodeResources.recordPod(pod)
+}
+
+// captureAllContainerLogs captures the current and previous logs of all the containers of the given pod in
+// podLogsDir. If logs are followed, current logs of all the containers are followed concurrently within a single
+// follow window, so that each pod takes the follow duration once.
+func captureAllContainerLogs(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	pod *corev1.Pod, podLogsDir string, budget *logBudget) error {
+	containerNames := make([]string, 0, len(pod.Spec.Containers)+len(pod.Spec.InitContainers))
+
+	for containerIndex := range pod.Spec.Containers {
+		containerNames = append(containerNames, pod.Spec.Containers[containerIndex].Name)
 	}
 
-	if len(pods.Items) == 0 {
-		logger.Info("No resource found in namespace", zap.String("kind", "Pod"),
-			zap.String("namespace", ns))
-		return nil
+	for initContainerIndex := range pod.Spec.InitContainers {
+		containerNames = append(containerNames, pod.Spec.InitContainers[initContainerIndex].Name)
 	}
 
-	for podIndex := range pods.Items {
-		podData, err := yaml.Marshal(pods.Items[podIndex])
-		if err != nil {
+	// Ephemeral containers are never restarted, so they do not have previous logs
+	currentNames := containerNames
+	for ephemeralContainerIndex := range pod.Spec.EphemeralContainers {
+		currentNames = append(currentNames, pod.Spec.EphemeralContainers[ephemeralContainerIndex].Name)
+	}
+
+	if params.FollowDuration > 0 {
+		if err := followContainerLogs(ctx, params, state, pod, currentNames, podLogsDir, budget); err != nil {
 			return err
 		}
+	} else {
+		for _, containerName := range currentNames {
+			if err := captureContainerLogs(ctx, params, state, pod, containerName, pod.Namespace,
+				podLogsDir, false, budget); err != nil {
+				return err
+			}
+		}
+	}
 
-		podLogsDir := filepath.Join(rootOutputPath, KindDirNames[internal.PodKind], pods.Items[podIndex].Name, "logs")
-		if err := os.MkdirAll(podLogsDir, os.ModePerm); err != nil {
+	for _, containerName := range containerNames {
+		if err := captureContainerLogs(ctx, params, state, pod, containerName, pod.Namespace,
+			podLogsDir, true, budget); err != nil {
 			return err
 		}
+	}
 
-		fileName := filepath.Join(podLogsDir, "..", pods.Items[podIndex].Name+FileSuffix)
+	return nil
+}
 
-		if err := populateScraperDir(podData, fileName); err != nil {
-			return err
-		}
+// followContainerLogs follows the current logs of the given containers of the pod concurrently, till all of them
+// end or the follow duration elapses.
+func followContainerLogs(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	pod *corev1.Pod, containerNames []string, podLogsDir string, budget *logBudget) error {
+	// a single deadline for all the containers, the streams are closed once it is reached
+	followCtx, cancel := context.WithTimeout(ctx, params.FollowDuration)
+	defer cancel()
 
-		for containerIndex := range pods.Items[podIndex].Spec.Containers {
-			containerName := pods.Items[podIndex].Spec.Containers[containerIndex].Name
-			if err := captureContainerLogs(logger, clientSet, pods.Items[podIndex].Name, containerName, ns,
-				podLogsDir, false); err != nil {
-				return err
-			}
+	var wg sync.WaitGroup
 
-			if err := captureContainerLogs(logger, clientSet, pods.Items[podIndex].Name, containerName, ns,
-				podLogsDir, true); err != nil {
-				return err
-			}
-		}
+	errCh := make(chan error, len(containerNames))
 
-		for initContainerIndex := range pods.Items[podIndex].Spec.InitContainers {
-			initContainerName := pods.Items[podIndex].Spec.InitContainers[initContainerIndex].Name
-			if err := captureContainerLogs(logger, clientSet, pods.Items[podIndex].Name, initContainerName, ns,
-				podLogsDir, false); err != nil {
-				return err
-			}
+	for _, containerName := range containerNames {
+		wg.Add(1)
 
-			if err := captureContainerLogs(logger, clientSet, pods.Items[podIndex].Name, initContainerName, ns,
-				podLogsDir, true); err != nil {
-				return err
+		go func() {
+			defer wg.Done()
+
+			if err := captureContainerLogs(followCtx, params, state, pod, containerName, pod.Namespace,
+				podLogsDir, false, budget); err != nil {
+				errCh <- err
 			}
-		}
+		}()
 	}
 
-	logger.Info("Successfully saved ", zap.String("kind", internal.PodKind),
-		zap.Int("number of objects", len(pods.Items)), zap.String("namespace", ns))
+	wg.Wait()
+	close(errCh)
 
-	return nil
+	return <-errCh
+}
+
+// writePod writes the pod in its directory, or appends it to the pods file in consolidate mode, or records it in
+// the snapshot in single JSON mode.
+func writePod(params *configuration.Parameters, state *collectionState, pod *corev1.Pod, rootOutputPath,
+	podLogsDir string) error {
+	obj, err := redactPod(state, pod)
+	if err != nil {
+		return err
+	}
+
+	if params.SingleJSON {
+		state.snapshot.add(internal.PodKind, obj)
+		return nil
+	}
+
+	if params.Consolidate {
+		return serializeAndAppend(obj, filepath.Join(rootOutputPath, KindDirNames[internal.PodKind]+JSONLinesSuffix))
+	}
+
+	podData, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	return populateScraperDir(podData,
+		filepath.Join(podLogsDir, "..", objectFileName(params, pod, internal.PodKind)+FileSuffix))
 }
 
-func captureContainerLogs(logger *zap.Logger, clientSet *kubernetes.Clientset, podName, containerName, ns,
-	podLogsDir string, previous bool) error {
+func captureContainerLogs(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	pod *corev1.Pod, containerName, ns, podLogsDir string, previous bool, budget *logBudget) error {
+	logger := params.Logger
+	podName := pod.Name
+	// Logs of previous containers can not be followed as they are already terminated
+	follow := params.FollowDuration > 0 && !previous
+
 	podLogOpts := corev1.PodLogOptions{
-		Container: containerName,
-		Previous:  previous,
+		Container:  containerName,
+		Previous:   previous,
+		Follow:     follow,
+		Timestamps: params.LogTimestamps,
+	}
+
+	if params.SinceRestart && !previous {
+		podLogOpts.SinceTime = containerStartTime(pod, containerName)
+	}
+
+	if budget != nil {
+		limitBytes, ok := budget.reserve(podName, containerName, previous)
+		if !ok {
+			return nil
+		}
+
+		podLogOpts.LimitBytes = &limitBytes
 	}
-	req := clientSet.CoreV1().Pods(ns).GetLogs(podName, &podLogOpts)
 
-	podLogs, reqErr := req.Stream(context.TODO())
+	req := params.ClientSet.CoreV1().Pods(ns).GetLogs(podName, &podLogOpts)
+
+	podLogs, reqErr := req.Stream(ctx)
 	if reqErr != nil {
 		if apierrors.IsBadRequest(reqErr) && previous {
 			logger.Debug("Previous container's logs not found ", zap.String("container", containerName),
@@ -441,22 +1860,38 @@ func captureContainerLogs(logger *zap.Logger, clientSet *kubernetes.Clientset, p
 			return nil
 		}
 
-		logger.Error("Could not fetch container's logs ", zap.String("container", containerName),
-			zap.Bool("previous", previous), zap.Error(reqErr))
+		state.failures.record(logger, internal.PodKind, ns, podName,
+			fmt.Errorf("could not fetch logs of container %s (previous %t): %v", containerName, previous, reqErr))
 
 		return nil
 	}
 
 	buf := new(bytes.Buffer)
-	if _, err := io.Copy(buf, podLogs); err != nil {
-		return err
+
+	if follow {
+		if err := FollowLogs(ctx, podLogs, buf, params.FollowDuration); err != nil {
+			return err
+		}
+	} else {
+		if _, err := io.Copy(buf, podLogs); err != nil {
+			return err
+		}
+
+		if err := podLogs.Close(); err != nil {
+			return err
+		}
 	}
 
-	if err := podLogs.Close(); err != nil {
-		return err
+	if budget != nil {
+		budget.consume(buf, podName, containerName, previous)
 	}
 
 	if previous {
+		// Containers which never restarted have no previous logs, skip those to avoid empty previous directories
+		if buf.Len() == 0 {
+			return nil
+		}
+
 		podLogsDir = filepath.Join(podLogsDir, "previous")
 		if err := os.MkdirAll(podLogsDir, os.ModePerm); err != nil {
 			return err
@@ -464,8 +1899,88 @@ func captureContainerLogs(logger *zap.Logger, clientSet *kubernetes.Clientset, p
 	}
 
 	fileName := filepath.Join(podLogsDir, containerName+".log")
+	logs := redactLogs(buf.Bytes(), params.LogRedactions)
+
+	if params.LogGrep == nil {
+		return populateScraperDir(logs, fileName)
+	}
+
+	if params.KeepFullLogs {
+		if err := populateScraperDir(logs, filepath.Join(podLogsDir, containerName+FullLogSuffix)); err != nil {
+			return err
+		}
+	}
+
+	return populateScraperDir(grepLines(logs, params.LogGrep), fileName)
+}
+
+// grepLines returns the lines of the given logs which match the regular expression.
+func grepLines(logs []byte, re *regexp.Regexp) []byte {
+	var matched []byte
+
+	for _, line := range bytes.SplitAfter(logs, []byte("\n")) {
+		if re.Match(line) {
+			matched = append(matched, line...)
+		}
+	}
+
+	return matched
+}
+
+// containerStartTime returns the time of the last start of the given container, nil if it is not known.
+// Containers which are not running are started after their last termination.
+func containerStartTime(pod *corev1.Pod, containerName string) *metav1.Time {
+	statuses := allContainerStatuses(pod)
+
+	for idx := range statuses {
+		if statuses[idx].Name != containerName {
+			continue
+		}
+
+		state := statuses[idx].State
+
+		switch {
+		case state.Running != nil:
+			return &state.Running.StartedAt
+		case state.Terminated != nil:
+			return &state.Terminated.StartedAt
+		case statuses[idx].LastTerminationState.Terminated != nil:
+			return &statuses[idx].LastTerminationState.Terminated.FinishedAt
+		}
+
+		return nil
+	}
 
-	return populateScraperDir(buf.Bytes(), fileName)
+	return nil
+}
+
+// FollowLogs copies the given log stream into the writer until the stream ends or the given duration elapses,
+// whichever happens first. The stream is closed in both the cases.
+func FollowLogs(ctx context.Context, stream io.ReadCloser, w io.Writer, duration time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := io.Copy(w, stream)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if closeErr := stream.Close(); err == nil {
+			err = closeErr
+		}
+
+		return err
+	case <-ctx.Done():
+		// Closing the stream unblocks the copy, wait for it so that the writer is no longer in use
+		_ = stream.Close()
+		<-done
+
+		return nil
+	}
 }
 
 func populateScraperDir(data []byte, fileName string) error {
@@ -479,13 +1994,66 @@ func populateScraperDir(data []byte, fileName string) error {
 	return nil
 }
 
-func compress(src string, buf io.Writer) error {
-	// tar > gzip > buf
-	zr := gzip.NewWriter(buf)
+// newCompressor returns the writer of the given compression algorithm, gzip by default.
+// If parallel is set, gzip blocks are compressed concurrently using all the CPUs. zstd is always concurrent.
+// If fast is set, the fastest level is used, for bundles whose big logs are already gzipped individually.
+func newCompressor(w io.Writer, compression string, parallel, fast bool) (io.WriteCloser, error) {
+	if compression == configuration.CompressionZstd {
+		if fast {
+			return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		}
+
+		return zstd.NewWriter(w)
+	}
+
+	level := gzip.DefaultCompression
+	if fast {
+		level = gzip.BestSpeed
+	}
+
+	if parallel {
+		zw, err := pgzip.NewWriterLevel(w, level)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := zw.SetConcurrency(parallelCompressBlockSize, runtime.GOMAXPROCS(0)); err != nil {
+			return nil, err
+		}
+
+		return zw, nil
+	}
+
+	return gzip.NewWriterLevel(w, level)
+}
+
+// compress writes the tar of the given directory, relative to src, in buf with the compression of params. The
+// .log.gz files of PerFileGzip gain nothing from being compressed twice, so the fastest level is used with it.
+func compress(params *configuration.Parameters, src, dir string, buf io.Writer) error {
+	// tar > gzip or zstd > buf
+	zr, err := newCompressor(buf, params.Compression, params.ParallelCompress, params.PerFileGzip)
+	if err != nil {
+		return err
+	}
+
 	tw := tar.NewWriter(zr)
+	if err := addToTar(tw, src, dir, ""); err != nil {
+		return err
+	}
+	// produce tar
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	// produce gzip or zstd
+	return zr.Close()
+}
+
+// addToTar writes the given directory, relative to src, into the tar under the given prefix.
+func addToTar(tw *tar.Writer, src, dir, prefix string) error {
 	// walk through every file in the folder
-	rootOutputPath := filepath.Join(src, RootOutputDir)
-	err := filepath.Walk(rootOutputPath, func(file string, fi os.FileInfo, err error) error {
+	rootOutputPath := filepath.Join(src, dir)
+
+	return filepath.Walk(rootOutputPath, func(file string, fi os.FileInfo, err error) error {
 		// generate tar header
 		header, fileErr := tar.FileInfoHeader(fi, file)
 		if fileErr != nil {
@@ -495,7 +2063,14 @@ func compress(src string, buf io.Writer) error {
 		// must provide real name
 		// (see https://golang.org/src/archive/tar/common.go?#L626)
 
-		header.Name = strings.TrimPrefix(file, src)
+		header.Name, fileErr = filepath.Rel(src, file)
+		if fileErr != nil {
+			return fileErr
+		}
+
+		header.Name = path.Join(prefix, filepath.ToSlash(header.Name))
+		// PAX keeps names longer than 100 characters and UTF-8 names intact
+		header.Format = tar.FormatPAX
 		// write header
 		if fileErr := tw.WriteHeader(header); fileErr != nil {
 			return fileErr
@@ -514,26 +2089,118 @@ func compress(src string, buf io.Writer) error {
 
 		return nil
 	})
+}
 
-	if err != nil {
-		return err
+// stripManagedFields removes metadata.managedFields of the object before it is serialized, unless these are to be kept.
+func stripManagedFields(params *configuration.Parameters, obj metav1.Object) {
+	if !params.KeepManagedFields {
+		obj.SetManagedFields(nil)
 	}
-	// produce tar
-	if err := tw.Close(); err != nil {
-		return err
-	}
-	// produce gzip
-	return zr.Close()
 }
 
-func serializeAndWrite(obj unstructured.Unstructured, objOutputDir string) error {
+// serializeAndWrite writes the object in its own file, after masking the fields matching the redaction rules.
+func serializeAndWrite(params *configuration.Parameters, state *collectionState, obj unstructured.Unstructured,
+	objOutputDir string) error {
+	redactObject(state, obj.GetKind(), obj.Object)
+
 	clusterData, err := yaml.Marshal(obj)
 	if err != nil {
 		return err
 	}
 
 	fileName := filepath.Join(objOutputDir,
-		obj.GetName()+FileSuffix)
+		objectFileName(params, &obj, obj.GetKind())+FileSuffix)
 
 	return populateScraperDir(clusterData, fileName)
 }
+
+// serializeAndAppend appends the object as a single JSON line to the given file.
+// It is used in consolidate mode to keep all objects of a kind in one file.
+func serializeAndAppend(obj interface{}, fileName string) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Clean(fileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// logBudget limits the total bytes of container logs captured in a namespace. It is safe to use concurrently, as
+// followed containers of a pod are captured concurrently.
+type logBudget struct {
+	truncated []string
+	limit     int64
+	remaining int64
+	mutex     sync.Mutex
+}
+
+// newLogBudget returns nil if there is no limit.
+func newLogBudget(limit int64) *logBudget {
+	if limit <= 0 {
+		return nil
+	}
+
+	return &logBudget{limit: limit, remaining: limit}
+}
+
+// reserve returns the bytes to be read for the logs of the given container, one byte more than the remaining budget
+// to know whether the logs are truncated. It returns false, recording the logs as not captured, if the budget is
+// exhausted.
+func (b *logBudget) reserve(podName, containerName string, previous bool) (int64, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.remaining <= 0 {
+		b.truncate(podName, containerName, previous)
+		return 0, false
+	}
+
+	return b.remaining + 1, true
+}
+
+// consume truncates the logs to the remaining budget and deducts them from the budget.
+func (b *logBudget) consume(buf *bytes.Buffer, podName, containerName string, previous bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if int64(buf.Len()) > b.remaining {
+		buf.Truncate(int(b.remaining))
+		b.truncate(podName, containerName, previous)
+	}
+
+	b.remaining -= int64(buf.Len())
+}
+
+func (b *logBudget) truncate(podName, containerName string, previous bool) {
+	name := podName + "/" + containerName
+	if previous {
+		name += " (previous)"
+	}
+
+	b.truncated = append(b.truncated, name)
+}
+
+// marker returns the content of LogsTruncatedFile listing containers whose logs are truncated or skipped.
+func (b *logBudget) marker(ns string) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "Pod logs of namespace %s are truncated at %d bytes as per --max-namespace-log-bytes.\n",
+		ns, b.limit)
+	fmt.Fprintln(&buf, "Logs of the following containers are truncated or not captured:")
+
+	for _, name := range b.truncated {
+		fmt.Fprintln(&buf, name)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// OperatorGroup is the API group of the operator CRDs
+const OperatorGroup = "asdb.aerospike.com"
+
+// operatorKinds are the kinds of the operator CRDs, which are skipped if the CRDs are not installed
+var operatorKinds = sets.New(internal.AerospikeClusterKind, internal.BackupServiceKind)
+
+// checkOperatorCRDs checks using discovery if OperatorGroup is served by the cluster. If not, operatorKinds are
+// skipped and recorded in the report, so that core resources are still collected.
+// The CRDs are assumed to be installed if discovery fails.
+func checkOperatorCRDs(params *configuration.Parameters, state *collectionState, report *CollectionReport) {
+	groups, err := params.ClientSet.Discovery().ServerGroups()
+	if err != nil {
+		params.Logger.Warn("Not able to discover API groups, assuming operator CRDs are installed", zap.Error(err))
+		return
+	}
+
+	for idx := range groups.Groups {
+		if groups.Groups[idx].Name == OperatorGroup {
+			return
+		}
+	}
+
+	params.Logger.Warn("operator CRDs not found; skipping Aerospike resources", zap.String("group", OperatorGroup))
+
+	for _, kind := range sets.List(operatorKinds) {
+		state.skippedKinds.Insert(kind)
+		report.skipKind(kind, fmt.Errorf("operator CRDs not found, API group %s is not served", OperatorGroup))
+	}
+}
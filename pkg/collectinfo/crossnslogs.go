@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// CrossNamespaceLogsDir has the logs of the pods matching the log selector, from all namespaces.
+const CrossNamespaceLogsDir = "cross_namespace_logs"
+
+// captureCrossNamespaceLogs captures the container logs of the pods of all namespaces matching the log selector
+// in CrossNamespaceLogsDir/<namespace>/<pod name>. Pod objects are not captured.
+func captureCrossNamespaceLogs(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	rootOutputPath string) error {
+	logger := params.Logger
+
+	pods, err := params.ClientSet.CoreV1().Pods(metav1.NamespaceAll).List(ctx,
+		metav1.ListOptions{LabelSelector: params.LogSelector})
+	if err != nil {
+		logger.Error("Not able to list ", zap.String("kind", internal.PodKind),
+			zap.String("labelSelector", params.LogSelector), zap.Error(err))
+		return err
+	}
+
+	for idx := range pods.Items {
+		podLogsDir := filepath.Join(rootOutputPath, CrossNamespaceLogsDir, pods.Items[idx].Namespace,
+			pods.Items[idx].Name)
+		if err := os.MkdirAll(podLogsDir, os.ModePerm); err != nil {
+			return err
+		}
+
+		if err := captureAllContainerLogs(ctx, params, state, &pods.Items[idx], podLogsDir, nil); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Successfully saved logs of pods matching log selector", zap.String("labelSelector",
+		params.LogSelector), zap.Int("pods", len(pods.Items)))
+
+	return nil
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const (
+	// CrossNamespaceRefsDir has the Secrets and ConfigMaps of other namespaces referenced by the Aerospike CRs of a
+	// namespace, in <namespace>/<kind>/<name>.yaml.
+	CrossNamespaceRefsDir = "cross_namespace_refs"
+	// CrossNamespaceRefsFile lists the references of the Aerospike CRs of a namespace to other namespaces, with the
+	// field of each reference and whether the referenced object is found.
+	CrossNamespaceRefsFile = "cross_namespace_refs.txt"
+)
+
+// crossRefKeys are the keys of the name and namespace of a reference to each kind, like secretName and
+// secretNamespace of the certificates of operatorClientCert.
+var crossRefKeys = []struct {
+	kind    string
+	nameKey string
+	nsKey   string
+}{
+	{kind: internal.SecretKind, nameKey: "secretName", nsKey: "secretNamespace"},
+	{kind: internal.ConfigMapKind, nameKey: "configMapName", nsKey: "configMapNamespace"},
+}
+
+// crossRef is a reference of an Aerospike CR to an object of another namespace.
+type crossRef struct {
+	ownerKind string
+	ownerName string
+	field     string
+	kind      string
+	namespace string
+	name      string
+}
+
+// crossRefState records the cross namespace references of the collected Aerospike CRs of each namespace.
+type crossRefState struct {
+	refs  map[string][]crossRef
+	mutex sync.Mutex
+}
+
+func newCrossRefState() *crossRefState {
+	return &crossRefState{refs: map[string][]crossRef{}}
+}
+
+// record records the references in the spec of the given Aerospike CR to Secrets and ConfigMaps of other namespaces.
+func (s *crossRefState) record(kind string, obj *unstructured.Unstructured) {
+	spec, ok := obj.Object["spec"]
+	if !ok {
+		return
+	}
+
+	refs := findCrossRefs(spec, "spec", "", obj.GetNamespace())
+	if len(refs) == 0 {
+		return
+	}
+
+	for idx := range refs {
+		refs[idx].ownerKind, refs[idx].ownerName = kind, obj.GetName()
+	}
+
+	s.mutex.Lock()
+	s.refs[obj.GetNamespace()] = append(s.refs[obj.GetNamespace()], refs...)
+	s.mutex.Unlock()
+}
+
+// findCrossRefs returns the references to objects of namespaces other than ns in the given value at the given path.
+// A reference is a map with the keys of crossRefKeys, or with name and namespace keys in a field whose name has
+// secret or configMap, like secretRef.
+func findCrossRefs(value interface{}, path, field, ns string) []crossRef {
+	var refs []crossRef
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := crossRefOf(typed, field); ok && ref.namespace != ns {
+			ref.field = path
+			refs = append(refs, ref)
+		}
+
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			refs = append(refs, findCrossRefs(typed[key], path+"."+key, key, ns)...)
+		}
+	case []interface{}:
+		for idx := range typed {
+			refs = append(refs, findCrossRefs(typed[idx], fmt.Sprintf("%s[%d]", path, idx), field, ns)...)
+		}
+	}
+
+	return refs
+}
+
+// crossRefOf returns the namespaced reference of the given map, which is in the given field.
+func crossRefOf(obj map[string]interface{}, field string) (crossRef, bool) {
+	for _, keys := range crossRefKeys {
+		name, _ := obj[keys.nameKey].(string)
+		namespace, _ := obj[keys.nsKey].(string)
+
+		if name != "" && namespace != "" {
+			return crossRef{kind: keys.kind, name: name, namespace: namespace}, true
+		}
+	}
+
+	name, _ := obj["name"].(string)
+	namespace, _ := obj["namespace"].(string)
+
+	if name == "" || namespace == "" {
+		return crossRef{}, false
+	}
+
+	switch field = strings.ToLower(field); {
+	case strings.Contains(field, "secret"):
+		return crossRef{kind: internal.SecretKind, name: name, namespace: namespace}, true
+	case strings.Contains(field, "configmap"):
+		return crossRef{kind: internal.ConfigMapKind, name: name, namespace: namespace}, true
+	}
+
+	return crossRef{}, false
+}
+
+// write captures the objects referenced by the Aerospike CRs of the given namespace in CrossNamespaceRefsDir, with
+// the redaction rules of their kind applied, and lists the references in CrossNamespaceRefsFile. Objects which can
+// not be read are recorded in the report.
+func (s *crossRefState) write(ctx context.Context, params *configuration.Parameters, state *collectionState, ns,
+	rootOutputPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	refs := s.refs[ns]
+	if len(refs) == 0 {
+		return nil
+	}
+
+	statuses := map[string]string{}
+
+	for idx := range refs {
+		ref := &refs[idx]
+
+		key := ref.kind + "/" + ref.namespace + "/" + ref.name
+		if _, ok := statuses[key]; ok {
+			continue
+		}
+
+		status, err := captureCrossRef(ctx, params, state, ref, filepath.Join(rootOutputPath, CrossNamespaceRefsDir))
+		if err != nil {
+			return err
+		}
+
+		statuses[key] = status
+	}
+
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "REFERENCED BY\tFIELD\tKIND\tNAMESPACE\tNAME\tSTATUS")
+
+	for idx := range refs {
+		ref := &refs[idx]
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\t%s\t%s\n", ref.ownerKind, ref.ownerName, ref.field, ref.kind,
+			ref.namespace, ref.name, statuses[ref.kind+"/"+ref.namespace+"/"+ref.name])
+	}
+
+	_ = w.Flush()
+
+	return populateScraperDir(buf.Bytes(), filepath.Join(rootOutputPath, CrossNamespaceRefsFile))
+}
+
+// captureCrossRef captures the referenced object in <namespace>/<kind dir> of the given directory, and returns
+// whether it is collected.
+func captureCrossRef(ctx context.Context, params *configuration.Parameters, state *collectionState, ref *crossRef,
+	refsDir string) (string, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(ref.kind))
+
+	if err := params.K8sClient.Get(ctx, types.NamespacedName{Namespace: ref.namespace, Name: ref.name},
+		obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "not found", nil
+		}
+
+		state.failures.record(params.Logger, ref.kind, ref.namespace, ref.name, err)
+
+		return "failed", nil
+	}
+
+	kindDir := filepath.Join(refsDir, ref.namespace, KindDirNames[ref.kind])
+	if err := os.MkdirAll(kindDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	stripManagedFields(params, obj)
+
+	if err := serializeAndWrite(params, state, *obj, kindDir); err != nil {
+		return "", err
+	}
+
+	return "collected", nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+// checkDiscovery discovers the API resources served by the cluster in a best-effort way. During control plane
+// incidents, like an unavailable aggregated apiserver, discovery of some group versions fails while the rest are
+// still discovered. Kinds of the failed group versions are skipped and recorded in the report, so that the rest of
+// the kinds are still collected. If discovery fails altogether, all the kinds are still tried.
+func checkDiscovery(params *configuration.Parameters, state *collectionState, report *CollectionReport) {
+	_, _, err := params.ClientSet.Discovery().ServerGroupsAndResources()
+	if err == nil {
+		return
+	}
+
+	var failed *discovery.ErrGroupDiscoveryFailed
+	if !errors.As(err, &failed) {
+		params.Logger.Warn("Not able to discover API resources", zap.Error(err))
+		return
+	}
+
+	for gv, gvErr := range failed.Groups {
+		params.Logger.Warn("Not able to discover API group version, skipping its kinds",
+			zap.String("groupVersion", gv.String()), zap.Error(gvErr))
+	}
+
+	for _, gvks := range [][]schema.GroupVersionKind{nsScopedGVKs(params), clusterScopedGVKs(params)} {
+		for _, gvk := range gvks {
+			gvErr, ok := failed.Groups[gvk.GroupVersion()]
+			if !ok || state.skippedKinds.Has(gvk.Kind) {
+				continue
+			}
+
+			state.skippedKinds.Insert(gvk.Kind)
+			report.skipKind(gvk.Kind, fmt.Errorf("discovery of %s failed: %v", gvk.GroupVersion(), gvErr))
+		}
+	}
+}
+
+// isKindSkipped returns true if the given kind is not served as per discovery, so it is not collected.
+func (s *collectionState) isKindSkipped(kind string) bool {
+	return s.skippedKinds.Has(kind)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// listEventsByUID lists events of the given namespace matching the field selector
+// and groups them by UID of the involved object.
+func listEventsByUID(ctx context.Context, clientSet kubernetes.Interface, ns, fieldSelector string) (
+	map[types.UID][]corev1.Event, error) {
+	events, err := clientSet.CoreV1().Events(ns).List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	eventsByUID := make(map[types.UID][]corev1.Event)
+
+	for idx := range events.Items {
+		uid := events.Items[idx].InvolvedObject.UID
+		eventsByUID[uid] = append(eventsByUID[uid], events.Items[idx])
+	}
+
+	return eventsByUID, nil
+}
+
+// formatEvents formats events in a table similar to the events section of kubectl describe.
+func formatEvents(events []corev1.Event) []byte {
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(&events[i]).Before(eventTime(&events[j]))
+	})
+
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tFROM\tCOUNT\tMESSAGE")
+
+	for idx := range events {
+		event := &events[idx]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", eventTime(event).Format(time.RFC3339), event.Type,
+			event.Reason, eventSource(event), event.Count, strings.TrimSpace(event.Message))
+	}
+
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
+
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.FirstTimestamp.Time
+	}
+}
+
+func eventSource(event *corev1.Event) string {
+	if event.Source.Component != "" {
+		return event.Source.Component
+	}
+
+	return event.ReportingController
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const (
+	AerospikeConfFile = "aerospike.conf"
+	AsinfoConfigFile  = "asinfo_config.txt"
+	// AerospikeServerContainer is the container running Aerospike server in the pods created by the operator
+	AerospikeServerContainer = "aerospike-server"
+)
+
+// execConfigCommands are run in the Aerospike server container to capture its live config, the output of each is
+// written into the given file of the pod directory.
+var execConfigCommands = map[string][]string{
+	AerospikeConfFile: {"cat", "/etc/aerospike/aerospike.conf"},
+	AsinfoConfigFile:  {"asinfo", "-v", "get-config"},
+}
+
+// isAerospikePod returns true if the given pod is a running Aerospike server pod created by the operator.
+func isAerospikePod(pod *corev1.Pod) bool {
+	if _, ok := pod.Labels[ClusterNameLabel]; !ok || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+
+	for idx := range pod.Spec.Containers {
+		if pod.Spec.Containers[idx].Name == AerospikeServerContainer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// captureExecConfig captures the rendered aerospike.conf and the asinfo config of the given pod, by running
+// execConfigCommands in its Aerospike server container. Commands which fail are recorded in the report.
+func captureExecConfig(ctx context.Context, params *configuration.Parameters, state *collectionState, pod *corev1.Pod,
+	podDir string) error {
+	for file, command := range execConfigCommands {
+		output, err := execInContainer(ctx, params, pod.Namespace, pod.Name, AerospikeServerContainer, command,
+			nil)
+		if err != nil {
+			state.failures.record(params.Logger, internal.PodKind, pod.Namespace, pod.Name,
+				fmt.Errorf("could not run %q in container %s: %v", strings.Join(command, " "),
+					AerospikeServerContainer, err))
+
+			continue
+		}
+
+		if err := populateScraperDir(output, filepath.Join(podDir, file)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// execInContainer runs the command in the given container using the exec subresource and returns its stdout. The
+// given stdin, if any, is streamed to the command, which keeps it out of the exec URL unlike the command.
+func execInContainer(ctx context.Context, params *configuration.Parameters, ns, pod, container string,
+	command []string, stdin []byte) ([]byte, error) {
+	if params.NewExecutor == nil {
+		return nil, fmt.Errorf("executor is not configured")
+	}
+
+	req := params.ClientSet.CoreV1().RESTClient().Post().Resource("pods").Namespace(ns).Name(pod).
+		SubResource("exec").VersionedParams(&corev1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     stdin != nil,
+		Stdout:    true,
+		Stderr:    true,
+	}, scheme.ParameterCodec)
+
+	executor, err := params.NewExecutor(http.MethodPost, req.URL())
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	streamOptions := remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}
+	if stdin != nil {
+		streamOptions.Stdin = bytes.NewReader(stdin)
+	}
+
+	if err := executor.StreamWithContext(ctx, streamOptions); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+// validateExtraKinds rejects the extra kinds of params which are already collected by default.
+func validateExtraKinds(params *configuration.Parameters) error {
+	for idx := range params.ExtraKinds {
+		if _, ok := KindDirNames[params.ExtraKinds[idx].Kind]; ok {
+			return fmt.Errorf("invalid extra kind %q: kind is already collected", params.ExtraKinds[idx].Kind)
+		}
+	}
+
+	return nil
+}
+
+// extraGVKs returns the extra kinds of params of the given scope.
+func extraGVKs(params *configuration.Parameters, scope string) []schema.GroupVersionKind {
+	var gvks []schema.GroupVersionKind
+
+	for idx := range params.ExtraKinds {
+		if extraKind := &params.ExtraKinds[idx]; extraKind.Scope == scope {
+			gvks = append(gvks, schema.GroupVersionKind{Group: extraKind.Group, Version: extraKind.Version,
+				Kind: extraKind.Kind})
+		}
+	}
+
+	return gvks
+}
+
+// extraKindNamePrefix returns the name filter of the given extra kind, empty for the other kinds.
+func extraKindNamePrefix(params *configuration.Parameters, kind string) string {
+	for idx := range params.ExtraKinds {
+		if params.ExtraKinds[idx].Kind == kind {
+			return params.ExtraKinds[idx].NamePrefix
+		}
+	}
+
+	return ""
+}
+
+// kindDir returns the directory of the given kind. Directory of each extra kind is derived from its kind, for
+// example MyResource is written in myresources.
+func kindDir(kind string) string {
+	if dirName, ok := KindDirNames[kind]; ok {
+		return dirName
+	}
+
+	return kindDirName(kind)
+}
+
+// kindDirName returns the lower case plural of the given kind, similar to the resource name of the kind.
+func kindDirName(kind string) string {
+	name := strings.ToLower(kind)
+
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"),
+		strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case len(name) > 1 && strings.HasSuffix(name, "y") && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	default:
+		return name + "s"
+	}
+}
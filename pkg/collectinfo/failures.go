@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// CaptureFailure is an object or kind which could not be captured, along with the error.
+type CaptureFailure struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Error     string `json:"error"`
+}
+
+type failureRecorder struct {
+	failures []CaptureFailure
+	mutex    sync.Mutex
+}
+
+// record logs the failure to capture the given object and records it. Name is empty if the whole kind failed.
+func (r *failureRecorder) record(logger *zap.Logger, kind, ns, name string, err error) {
+	logger.Error("Not able to capture, skipping", zap.String("kind", kind), zap.String("namespace", ns),
+		zap.String("name", name), zap.Error(err))
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.failures = append(r.failures, CaptureFailure{Kind: kind, Namespace: ns, Name: name, Error: err.Error()})
+}
+
+func (r *failureRecorder) list() []CaptureFailure {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]CaptureFailure(nil), r.failures...)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"regexp"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+// unsafeFileNameChars are the characters replaced by _ in the file names generated from the output name template
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// objectFileName returns the file name of the given object without suffix, generated from the output name template.
+// It is the object name if no template is given.
+func objectFileName(params *configuration.Parameters, obj metav1.Object, kind string) string {
+	if params.OutputNameTemplate == "" {
+		return obj.GetName()
+	}
+
+	uid := string(obj.GetUID())
+
+	uid8 := uid
+	if len(uid8) > 8 {
+		uid8 = uid8[:8]
+	}
+
+	name := strings.NewReplacer(
+		configuration.PlaceholderNamespace, obj.GetNamespace(),
+		configuration.PlaceholderName, obj.GetName(),
+		configuration.PlaceholderUID, uid,
+		configuration.PlaceholderUID8, uid8,
+		configuration.PlaceholderKind, kind,
+	).Replace(params.OutputNameTemplate)
+
+	// separators of empty placeholders, like namespace of cluster scoped objects, are trimmed along with dots,
+	// so that the name is never . or ..
+	name = strings.Trim(unsafeFileNameChars.ReplaceAllString(name, "_"), "_.")
+	if name == "" {
+		return strings.Trim(unsafeFileNameChars.ReplaceAllString(obj.GetName(), "_"), "_.")
+	}
+
+	return name
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+const (
+	HealthDir             = "health"
+	ComponentStatusesFile = "componentstatuses.yaml"
+)
+
+// HealthEndpoints are the apiserver health endpoints captured in the health directory.
+var HealthEndpoints = []string{"readyz", "healthz", "livez"}
+
+// captureHealth captures the apiserver health endpoints and componentstatuses.
+// Endpoints which are not accessible are skipped with a warning.
+func captureHealth(ctx context.Context, params *configuration.Parameters, rootOutputPath string) error {
+	objOutputDir := filepath.Join(rootOutputPath, HealthDir)
+	if err := os.MkdirAll(objOutputDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	restClient := params.ClientSet.Discovery().RESTClient()
+
+	for _, endpoint := range HealthEndpoints {
+		// Unhealthy endpoints return an error along with the verbose check list, which is worth keeping
+		out, err := restClient.Get().AbsPath("/"+endpoint).Param("verbose", "").DoRaw(ctx)
+		if err != nil && len(out) == 0 {
+			params.Logger.Warn("Not able to fetch health endpoint", zap.String("endpoint", endpoint), zap.Error(err))
+			continue
+		}
+
+		if err := populateScraperDir(out, filepath.Join(objOutputDir, endpoint+".txt")); err != nil {
+			return err
+		}
+	}
+
+	//nolint:staticcheck // componentstatuses is deprecated but still served by most clusters
+	componentStatuses, err := params.ClientSet.CoreV1().ComponentStatuses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		params.Logger.Warn("Not able to list componentstatuses", zap.Error(err))
+	} else {
+		data, err := yaml.Marshal(componentStatuses)
+		if err != nil {
+			return err
+		}
+
+		if err := populateScraperDir(data, filepath.Join(objOutputDir, ComponentStatusesFile)); err != nil {
+			return err
+		}
+	}
+
+	params.Logger.Info("Successfully saved cluster health")
+
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const InitSummaryFile = "init_summary.txt"
+
+// initLogTailLines is the number of last log lines of each init container kept in InitSummaryFile
+const initLogTailLines = 20
+
+// writeInitSummary writes InitSummaryFile of the given Aerospike pod in its directory, with the state, exit codes
+// and tail of the logs captured in podLogsDir of each init container, as these carry the startup info of the
+// Aerospike server. Nothing is written if the pod has no init containers.
+func writeInitSummary(pod *corev1.Pod, podLogsDir string) error {
+	if _, ok := pod.Labels[ClusterNameLabel]; !ok || len(pod.Spec.InitContainers) == 0 {
+		return nil
+	}
+
+	statuses := make(map[string]*corev1.ContainerStatus, len(pod.Status.InitContainerStatuses))
+	for idx := range pod.Status.InitContainerStatuses {
+		statuses[pod.Status.InitContainerStatuses[idx].Name] = &pod.Status.InitContainerStatuses[idx]
+	}
+
+	var buf bytes.Buffer
+
+	for idx := range pod.Spec.InitContainers {
+		name := pod.Spec.InitContainers[idx].Name
+
+		fmt.Fprintf(&buf, "Init container: %s\n", name)
+
+		if status, ok := statuses[name]; ok {
+			fmt.Fprintf(&buf, "  State: %s\n", describeContainerState(&status.State))
+			fmt.Fprintf(&buf, "  Last state: %s\n", describeContainerState(&status.LastTerminationState))
+			fmt.Fprintf(&buf, "  Restarts: %d\n", status.RestartCount)
+		} else {
+			fmt.Fprintf(&buf, "  State: %s\n", valueOrNone(""))
+		}
+
+		tail, err := logTail(podLogsDir, name, initLogTailLines)
+		if err != nil {
+			return err
+		}
+
+		if len(tail) == 0 {
+			fmt.Fprintf(&buf, "  Log tail: %s\n\n", valueOrNone(""))
+			continue
+		}
+
+		fmt.Fprintf(&buf, "  Log tail (last %d lines):\n", len(tail))
+
+		for _, line := range tail {
+			fmt.Fprintf(&buf, "    %s\n", line)
+		}
+
+		buf.WriteString("\n")
+	}
+
+	return populateScraperDir(buf.Bytes(), filepath.Join(podLogsDir, "..", InitSummaryFile))
+}
+
+// describeContainerState describes the given container state along with its exit code and reason.
+func describeContainerState(state *corev1.ContainerState) string {
+	switch {
+	case state.Terminated != nil:
+		return fmt.Sprintf("Terminated (exit code %d, reason %s, finished %s)", state.Terminated.ExitCode,
+			valueOrNone(state.Terminated.Reason), state.Terminated.FinishedAt.UTC().Format("2006-01-02T15:04:05Z"))
+	case state.Waiting != nil:
+		return fmt.Sprintf("Waiting (reason %s)", valueOrNone(state.Waiting.Reason))
+	case state.Running != nil:
+		return "Running"
+	default:
+		return valueOrNone("")
+	}
+}
+
+// logTail returns the last lines of the logs of the given container captured in podLogsDir. The full logs are used
+// if these are kept along with the grepped logs. It returns nil if the logs are not captured.
+func logTail(podLogsDir, containerName string, lines int) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(podLogsDir, containerName+FullLogSuffix))
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = os.ReadFile(filepath.Join(podLogsDir, containerName+".log"))
+	}
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	logLines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(logLines) == 1 && logLines[0] == "" {
+		return nil, nil
+	}
+
+	if len(logLines) > lines {
+		logLines = logLines[len(logLines)-lines:]
+	}
+
+	return logLines, nil
+}
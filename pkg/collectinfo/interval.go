@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+// RunCollectInfoOnInterval runs the collection repeatedly, waiting params.Interval between the end of a collection
+// and the start of the next one, and keeps the archives of the last params.Count collections of this call in the
+// given path. Each collection is archived with its own timestamp. It runs the given number of collections, or till
+// ctx is done if runs is zero.
+func RunCollectInfoOnInterval(ctx context.Context, params *configuration.Parameters, path string, runs int) error {
+	// archives of each collection, oldest first
+	var runArchives [][]string
+
+	for run := 1; ; run++ {
+		params.RunTime = time.Now()
+
+		params.Logger.Info("Running collection", zap.Int("run", run),
+			zap.String("tar file", BundleTarName(params)))
+
+		archives, err := runCollectInfo(ctx, params, path)
+		if err != nil {
+			return err
+		}
+
+		if len(archives) > 0 {
+			runArchives = append(runArchives, archives)
+		}
+
+		// bundle directory of a failed collection would make the next collection fail
+		if err := removeFailedBundle(params, path); err != nil {
+			return err
+		}
+
+		if runArchives, err = pruneArchives(params, runArchives); err != nil {
+			return err
+		}
+
+		if runs > 0 && run >= runs {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(params.Interval):
+		}
+	}
+}
+
+// removeFailedBundle removes the bundle directory left in the given path by a collection which failed before
+// archiving it.
+func removeFailedBundle(params *configuration.Parameters, path string) error {
+	bundleDir := filepath.Join(path, BundleDirName(params))
+
+	if _, err := os.Stat(bundleDir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	params.Logger.Warn("Collection failed, removing its bundle directory", zap.String("path", bundleDir))
+
+	return os.RemoveAll(bundleDir)
+}
+
+// pruneArchives removes the archives of the given collections other than the ones of the last params.Count
+// collections, and returns the archives kept. Archives of all the parts of a collection are kept or removed together.
+// Archives of other collections in the output directory are not removed.
+func pruneArchives(params *configuration.Parameters, runArchives [][]string) ([][]string, error) {
+	if params.Count == 0 || len(runArchives) <= params.Count {
+		return runArchives, nil
+	}
+
+	for _, archives := range runArchives[:len(runArchives)-params.Count] {
+		for _, archive := range archives {
+			params.Logger.Info("Removing old archive", zap.String("tar file", archive))
+
+			if err := os.Remove(archive); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	return runArchives[len(runArchives)-params.Count:], nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LeaderFile is written in the directory of a pod holding a Lease, like the leader replica of the operator.
+const LeaderFile = "leader.txt"
+
+// listLeaseHolders lists Leases of the given namespace and maps them by the name of the holder pod.
+// Leader election uses <hostname>_<uuid> as holder identity, where hostname is the pod name.
+func listLeaseHolders(ctx context.Context, clientSet kubernetes.Interface, ns string) (
+	map[string][]coordinationv1.Lease, error) {
+	leases, err := clientSet.CoordinationV1().Leases(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	holders := make(map[string][]coordinationv1.Lease)
+
+	for idx := range leases.Items {
+		holder := leases.Items[idx].Spec.HolderIdentity
+		if holder == nil || *holder == "" {
+			continue
+		}
+
+		podName, _, _ := strings.Cut(*holder, "_")
+		holders[podName] = append(holders[podName], leases.Items[idx])
+	}
+
+	return holders, nil
+}
+
+// formatLeases formats the Leases held by a pod in a table.
+func formatLeases(leases []coordinationv1.Lease) []byte {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "LEASE\tHOLDER\tRENEW TIME\tTRANSITIONS")
+
+	for idx := range leases {
+		spec := &leases[idx].Spec
+
+		renewTime := ""
+		if spec.RenewTime != nil {
+			renewTime = spec.RenewTime.Format(time.RFC3339)
+		}
+
+		transitions := int32(0)
+		if spec.LeaseTransitions != nil {
+			transitions = *spec.LeaseTransitions
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", leases[idx].Name, *spec.HolderIdentity, renewTime, transitions)
+	}
+
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
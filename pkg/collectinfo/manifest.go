@@ -0,0 +1,237 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+const ManifestFile = "manifest.json"
+
+// ManifestEntry is a file of the bundle listed in ManifestFile, with its path relative to the manifest directory.
+type ManifestEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// ValidationReport lists the discrepancies between the manifests of a bundle and its files.
+type ValidationReport struct {
+	// Missing are the files listed in a manifest, but not present in the bundle
+	Missing []string `json:"missing,omitempty"`
+	// Unlisted are the files present in the bundle, but not listed in any manifest
+	Unlisted []string `json:"unlisted,omitempty"`
+	// SizeMismatch are the files whose size differs from the size listed in the manifest
+	SizeMismatch []string `json:"sizeMismatch,omitempty"`
+}
+
+// Valid tells whether the bundle matches its manifests.
+func (r *ValidationReport) Valid() bool {
+	return len(r.Missing) == 0 && len(r.Unlisted) == 0 && len(r.SizeMismatch) == 0
+}
+
+// writeManifests writes ManifestFile in the given bundle directory, listing all its files. If PerNamespaceArchive is
+// set, each namespace directory is archived separately and so has its own manifest.
+func writeManifests(params *configuration.Parameters, bundleDir string) error {
+	if !params.PerNamespaceArchive || params.AppendTo != "" {
+		return writeManifest(bundleDir, "")
+	}
+
+	nsScopedDir := filepath.Join(bundleDir, NamespaceScopedDir)
+
+	nsDirs, err := os.ReadDir(nsScopedDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for _, nsDir := range nsDirs {
+		if err := writeManifest(filepath.Join(nsScopedDir, nsDir.Name()), ""); err != nil {
+			return err
+		}
+	}
+
+	return writeManifest(bundleDir, nsScopedDir)
+}
+
+// writeManifest writes ManifestFile in the given directory, listing all the files present in it except the ones
+// of excludeDir.
+func writeManifest(dir, excludeDir string) error {
+	var entries []ManifestEntry
+
+	if err := filepath.WalkDir(dir, func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if file == excludeDir {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, ManifestEntry{Path: filepath.ToSlash(relPath), Size: info.Size()})
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return populateScraperDir(data, filepath.Join(dir, ManifestFile))
+}
+
+// ValidateBundle reads the given archive and verifies that every file listed in its manifests is present in the
+// archive with the listed size, and every file of the archive is listed in a manifest. Archives with appended or
+// per namespace bundles have a manifest for each bundle, listing the files of its directory.
+// An error is returned if the archive can not be read or has no manifest.
+func ValidateBundle(archive string) (ValidationReport, error) {
+	var report ValidationReport
+
+	listed, files, err := readBundle(archive)
+	if err != nil {
+		return report, err
+	}
+
+	if listed == nil {
+		return report, fmt.Errorf("no %s found in archive %s", ManifestFile, archive)
+	}
+
+	for name, listedSize := range listed {
+		size, ok := files[name]
+
+		switch {
+		case !ok:
+			report.Missing = append(report.Missing, name)
+		case size != listedSize:
+			report.SizeMismatch = append(report.SizeMismatch, name)
+		}
+	}
+
+	for name := range files {
+		if _, ok := listed[name]; !ok {
+			report.Unlisted = append(report.Unlisted, name)
+		}
+	}
+
+	sort.Strings(report.Missing)
+	sort.Strings(report.Unlisted)
+	sort.Strings(report.SizeMismatch)
+
+	return report, nil
+}
+
+// readBundle returns the sizes of the files listed in the manifests of the given archive, and of the rest of its files,
+// keyed by their path in the archive. Listed sizes are nil if the archive has no manifest.
+func readBundle(archive string) (listed, files map[string]int64, err error) {
+	file, err := os.Open(filepath.Clean(archive))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader
+
+	if archiveCompression(archive) == configuration.CompressionZstd {
+		zr, zErr := zstd.NewReader(file)
+		if zErr != nil {
+			return nil, nil, zErr
+		}
+		defer zr.Close()
+
+		reader = zr
+	} else {
+		gr, gErr := gzip.NewReader(file)
+		if gErr != nil {
+			return nil, nil, gErr
+		}
+		defer gr.Close()
+
+		reader = gr
+	}
+
+	tarReader := tar.NewReader(reader)
+	files = map[string]int64{}
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return listed, files, nil
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+
+		if path.Base(name) == ManifestFile {
+			var entries []ManifestEntry
+			if err := json.NewDecoder(tarReader).Decode(&entries); err != nil {
+				return nil, nil, fmt.Errorf("failed to read %s: %v", name, err)
+			}
+
+			if listed == nil {
+				listed = map[string]int64{}
+			}
+
+			for _, entry := range entries {
+				listed[path.Join(path.Dir(name), entry.Path)] = entry.Size
+			}
+
+			continue
+		}
+
+		// size is counted from the content, so that a truncated entry is detected
+		size, err := io.Copy(io.Discard, tarReader) //nolint:gosec // content is discarded
+		if err != nil {
+			return nil, nil, err
+		}
+
+		files[name] = size
+	}
+}
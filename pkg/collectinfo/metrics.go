@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+const (
+	// MetricsDir contains a CSV file of each metric queried from Prometheus
+	MetricsDir = "metrics"
+	// MetricsSuffix is the suffix of the metric files
+	MetricsSuffix = ".csv"
+	// metricsPoints is the number of points queried for each series over the range
+	metricsPoints = 120
+	// prometheusQueryTimeout is the timeout of each Prometheus query
+	prometheusQueryTimeout = 30 * time.Second
+)
+
+// PrometheusMetrics are the key Aerospike exporter metrics queried from Prometheus over the range
+var PrometheusMetrics = []string{
+	"aerospike_node_up",
+	"aerospike_node_stats_cluster_size",
+	"aerospike_node_stats_client_connections",
+	"aerospike_node_stats_system_free_mem_pct",
+	"aerospike_namespace_client_read_success",
+	"aerospike_namespace_client_read_error",
+	"aerospike_namespace_client_write_success",
+	"aerospike_namespace_client_write_error",
+	"aerospike_namespace_memory_used_bytes",
+	"aerospike_namespace_device_used_bytes",
+	"aerospike_namespace_stop_writes",
+	"aerospike_namespace_hwm_breached",
+}
+
+// captureMetrics queries PrometheusMetrics of the collected namespaces over the range given in params, and
+// writes each in its own CSV file. Remaining metrics are skipped if Prometheus is not reachable.
+func captureMetrics(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	rootOutputPath string) error {
+	logger := params.Logger.With(zap.String("prometheus", params.PrometheusURL))
+
+	promClient, err := api.NewClient(api.Config{Address: params.PrometheusURL})
+	if err != nil {
+		return err
+	}
+
+	metricsDir := filepath.Join(rootOutputPath, MetricsDir)
+	if err := os.MkdirAll(metricsDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	end := time.Now()
+	queryRange := promv1.Range{
+		Start: end.Add(-params.PrometheusRange),
+		End:   end,
+		Step:  max(params.PrometheusRange/metricsPoints, time.Second),
+	}
+	promAPI := promv1.NewAPI(promClient)
+	nsMatcher := namespaceMatcher(params.Namespaces)
+
+	for _, metric := range PrometheusMetrics {
+		query := fmt.Sprintf("%s{namespace=~%q}", metric, nsMatcher)
+
+		queryCtx, cancel := context.WithTimeout(ctx, prometheusQueryTimeout)
+		result, warnings, err := promAPI.QueryRange(queryCtx, query, queryRange)
+
+		cancel()
+
+		if err != nil {
+			var apiErr *promv1.Error
+			if !errors.As(err, &apiErr) {
+				logger.Warn("Prometheus is not reachable, skipping metrics", zap.Error(err))
+				return nil
+			}
+
+			state.failures.record(logger, "Metric", "", metric, err)
+
+			continue
+		}
+
+		if len(warnings) > 0 {
+			logger.Warn("Prometheus returned warnings", zap.String("query", query), zap.Strings("warnings", warnings))
+		}
+
+		matrix, ok := result.(model.Matrix)
+		if !ok {
+			state.failures.record(logger, "Metric", "", metric,
+				fmt.Errorf("unexpected result type %s of range query", result.Type()))
+
+			continue
+		}
+
+		if err := writeMetricCSV(matrix, filepath.Join(metricsDir, metric+MetricsSuffix)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// namespaceMatcher returns the regex matching the given namespaces exactly.
+func namespaceMatcher(namespaces sets.Set[string]) string {
+	names := sets.List(namespaces)
+	for idx := range names {
+		names[idx] = regexp.QuoteMeta(names[idx])
+	}
+
+	return strings.Join(names, "|")
+}
+
+// writeMetricCSV writes a row for each sample of the given series, with the series labels, the RFC3339
+// timestamp and the value.
+func writeMetricCSV(matrix model.Matrix, fileName string) error {
+	f, err := os.OpenFile(filepath.Clean(fileName), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+
+	if err := w.Write([]string{"series", "timestamp", "value"}); err != nil {
+		return err
+	}
+
+	for _, stream := range matrix {
+		series := stream.Metric.String()
+
+		for _, sample := range stream.Values {
+			if err := w.Write([]string{series, sample.Timestamp.Time().UTC().Format(time.RFC3339),
+				strconv.FormatFloat(float64(sample.Value), 'f', -1, 64)}); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
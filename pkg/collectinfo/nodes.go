@@ -0,0 +1,186 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// recordAerospikeNode adds the node of the given pod to the node selection set, if it is an Aerospike pod.
+func recordAerospikeNode(state *collectionState, pod *corev1.Pod) {
+	if _, ok := pod.Labels[ClusterNameLabel]; !ok || pod.Spec.NodeName == "" {
+		return
+	}
+
+	state.selectionMutex.Lock()
+	state.aerospikeNodeNameSet.Insert(pod.Spec.NodeName)
+	state.selectionMutex.Unlock()
+}
+
+// listAerospikeNodeNames adds nodes of the Aerospike pods in the given namespace to the node selection set.
+func listAerospikeNodeNames(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	ns string) error {
+	pods, err := params.ClientSet.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: ClusterNameLabel})
+	if err != nil {
+		params.Logger.Error("Not able to list ", zap.String("kind", internal.PodKind), zap.Error(err))
+		return err
+	}
+
+	for idx := range pods.Items {
+		if params.ClusterName != "" && !isClusterObject(params, state, internal.PodKind, &pods.Items[idx]) {
+			continue
+		}
+
+		recordAerospikeNode(state, &pods.Items[idx])
+	}
+
+	return nil
+}
+
+// isSelectedNode returns true if the given node is to be collected. Nodes are already filtered by the node
+// selector when listed, so only the nodes hosting Aerospike pods are checked here.
+func isSelectedNode(params *configuration.Parameters, state *collectionState, node metav1.Object) bool {
+	return !params.AerospikeNodes || state.aerospikeNodeNameSet.Has(node.GetName())
+}
+
+// nodeSummaryArgs returns kubectl arguments to get only the selected nodes in the summary. It returns false if
+// no node is selected. kubectl does not allow names along with a selector, so nodes hosting Aerospike pods are
+// given by name, ignoring the node selector.
+func nodeSummaryArgs(params *configuration.Parameters, state *collectionState) ([]string, bool) {
+	if params.AerospikeNodes {
+		return sets.List(state.aerospikeNodeNameSet), state.aerospikeNodeNameSet.Len() > 0
+	}
+
+	if params.NodeSelector != "" {
+		return []string{"-l", params.NodeSelector}, true
+	}
+
+	return nil, true
+}
+
+// captureTargetNode captures the target node with its conditions, and the events involving it or its pods reported
+// by its kubelet, in the directory of the node in the cluster scope.
+func captureTargetNode(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	rootOutputPath string) error {
+	logger := params.Logger
+	nodeName := params.TargetNode
+
+	nodeDir := filepath.Join(rootOutputPath, ClusterScopedDir, KindDirNames[internal.NodeKind], nodeName)
+	if err := os.MkdirAll(nodeDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// events are still captured if the node is gone, as those may explain why
+	node, err := params.ClientSet.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		state.failures.record(logger, internal.NodeKind, "", nodeName, err)
+	} else {
+		stripManagedFields(params, node)
+
+		nodeData, mErr := yaml.Marshal(node)
+		if mErr != nil {
+			return mErr
+		}
+
+		if wErr := populateScraperDir(nodeData, filepath.Join(nodeDir, nodeName+FileSuffix)); wErr != nil {
+			return wErr
+		}
+	}
+
+	nodeEvents, err := listNodeEvents(ctx, params, nodeName)
+	if err != nil {
+		state.failures.record(logger, internal.EventKind, "", "",
+			fmt.Errorf("could not list events of node %s: %v", nodeName, err))
+		return nil
+	}
+
+	if len(nodeEvents) == 0 {
+		logger.Info("No events found for node", zap.String("node", nodeName))
+		return nil
+	}
+
+	logger.Info("Successfully saved events of node", zap.String("node", nodeName),
+		zap.Int("events", len(nodeEvents)))
+
+	return populateScraperDir(formatEvents(nodeEvents), filepath.Join(nodeDir, EventsFile))
+}
+
+// listNodeEvents returns the events of the given node, and the events of the pods on it reported from the node,
+// like kubelet events. Events are listed by the names of the objects they involve, instead of listing all the
+// events of the cluster.
+func listNodeEvents(ctx context.Context, params *configuration.Parameters, nodeName string) ([]corev1.Event, error) {
+	events, err := params.ClientSet.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: involvedObjectSelector(internal.NodeKind, nodeName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nodeEvents := events.Items
+
+	pods, err := params.ClientSet.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range pods.Items {
+		podEvents, err := params.ClientSet.CoreV1().Events(pods.Items[idx].Namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: involvedObjectSelector(internal.PodKind, pods.Items[idx].Name),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for eventIdx := range podEvents.Items {
+			if isNodeEvent(&podEvents.Items[eventIdx], nodeName) {
+				nodeEvents = append(nodeEvents, podEvents.Items[eventIdx])
+			}
+		}
+	}
+
+	return nodeEvents, nil
+}
+
+// involvedObjectSelector returns the field selector of the events involving the object of the given kind and name.
+func involvedObjectSelector(kind, name string) string {
+	return fields.AndSelectors(fields.OneTermEqualSelector("involvedObject.kind", kind),
+		fields.OneTermEqualSelector("involvedObject.name", name)).String()
+}
+
+// isNodeEvent returns true if the given event is reported from the given node, like kubelet events of its pods, or
+// involves the node itself.
+func isNodeEvent(event *corev1.Event, nodeName string) bool {
+	if event.Source.Host == nodeName {
+		return true
+	}
+
+	return event.InvolvedObject.Kind == internal.NodeKind && event.InvolvedObject.Name == nodeName
+}
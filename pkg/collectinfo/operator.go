@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// OperatorName is the name of the operator Deployment and ServiceAccount of the standard install, and the name
+// prefix of the operator pods.
+const OperatorName = "aerospike-operator-controller-manager"
+
+// operatorObjectKinds are the kinds of the operator objects located using the operator selector
+var operatorObjectKinds = sets.New(internal.DeployKind, internal.PodKind, internal.ServiceAccountKind)
+
+// isOperatorObject returns true if the given object of the given kind belongs to the operator. Objects are
+// selected using the operator selector of params if given, otherwise using OperatorName.
+func isOperatorObject(params *configuration.Parameters, kind string, obj metav1.Object) bool {
+	if !operatorObjectKinds.Has(kind) {
+		return false
+	}
+
+	if params.OperatorSelector != "" {
+		// selector is validated while setting it
+		selector, _ := labels.Parse(params.OperatorSelector)
+
+		return selector.Matches(labels.Set(obj.GetLabels()))
+	}
+
+	if kind == internal.PodKind {
+		return strings.HasPrefix(obj.GetName(), OperatorName+"-")
+	}
+
+	return obj.GetName() == OperatorName
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const (
+	// OperatorMetricsFile has the controller-runtime metrics scraped from an operator pod.
+	OperatorMetricsFile = "operator_metrics.txt"
+	// OperatorPodPrefix is the name prefix of the pods of the operator deployment
+	OperatorPodPrefix = OperatorName
+	// OperatorMetricsPath is the path of the metrics endpoint of the operator
+	OperatorMetricsPath = "/metrics"
+	// defaultOperatorMetricsPort is the metrics port of the operator if the pod does not declare one
+	defaultOperatorMetricsPort = "8080"
+)
+
+// isOperatorPod returns true if the given pod is a running replica of the operator.
+func isOperatorPod(params *configuration.Parameters, pod *corev1.Pod) bool {
+	return isOperatorObject(params, internal.PodKind, pod) && pod.Status.Phase == corev1.PodRunning
+}
+
+// operatorMetricsEndpoint returns the scheme and port of the metrics endpoint of the given operator pod, which is
+// the container port named metrics, or https if the metrics are served behind a proxy.
+func operatorMetricsEndpoint(pod *corev1.Pod) (scheme, port string) {
+	scheme, port = "http", defaultOperatorMetricsPort
+
+	for idx := range pod.Spec.Containers {
+		for _, containerPort := range pod.Spec.Containers[idx].Ports {
+			switch containerPort.Name {
+			case "metrics":
+				return "http", strconv.Itoa(int(containerPort.ContainerPort))
+			case "https":
+				scheme, port = "https", strconv.Itoa(int(containerPort.ContainerPort))
+			}
+		}
+	}
+
+	return scheme, port
+}
+
+// captureOperatorMetrics scrapes the metrics endpoint of the given operator pod once through the pod proxy of the
+// apiserver, and writes it in OperatorMetricsFile of the pod directory. Failures are recorded in the report.
+func captureOperatorMetrics(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	pod *corev1.Pod, podDir string) error {
+	scheme, port := operatorMetricsEndpoint(pod)
+
+	data, err := params.ClientSet.CoreV1().Pods(pod.Namespace).ProxyGet(scheme, pod.Name, port,
+		OperatorMetricsPath, nil).DoRaw(ctx)
+	if err != nil {
+		state.failures.record(params.Logger, internal.PodKind, pod.Namespace, pod.Name,
+			fmt.Errorf("could not scrape operator metrics at %s://:%s%s: %v", scheme, port, OperatorMetricsPath, err))
+
+		return nil
+	}
+
+	return populateScraperDir(data, filepath.Join(podDir, OperatorMetricsFile))
+}
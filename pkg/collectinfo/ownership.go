@@ -0,0 +1,194 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	OwnershipJSONFile = "ownership.json"
+	OwnershipDotFile  = "ownership.dot"
+)
+
+// OwnershipNode is an object in the ownership graph, identified by its UID.
+type OwnershipNode struct {
+	UID       string `json:"uid"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Collected bool   `json:"collected"`
+}
+
+// OwnershipEdge links an owner to its dependent object using UIDs.
+type OwnershipEdge struct {
+	Owner      string `json:"owner"`
+	Dependent  string `json:"dependent"`
+	Controller bool   `json:"controller"`
+}
+
+// OwnershipGraph links collected objects by their metadata.ownerReferences.
+type OwnershipGraph struct {
+	Nodes []OwnershipNode `json:"nodes"`
+	Edges []OwnershipEdge `json:"edges"`
+}
+
+type ownershipGraph struct {
+	nodes map[string]*OwnershipNode
+	edges []OwnershipEdge
+	mutex sync.RWMutex
+}
+
+func newOwnershipGraph() *ownershipGraph {
+	return &ownershipGraph{nodes: map[string]*OwnershipNode{}}
+}
+
+// record adds the collected object and its owners to the graph.
+// Owners which are not collected are added using the information present in owner reference.
+func (g *ownershipGraph) record(kind string, obj metav1.Object) {
+	uid := string(obj.GetUID())
+	if uid == "" {
+		return
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.nodes[uid] = &OwnershipNode{
+		UID:       uid,
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Collected: true,
+	}
+
+	for _, ref := range obj.GetOwnerReferences() {
+		ownerUID := string(ref.UID)
+		if _, ok := g.nodes[ownerUID]; !ok {
+			g.nodes[ownerUID] = &OwnershipNode{
+				UID:       ownerUID,
+				Kind:      ref.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      ref.Name,
+			}
+		}
+
+		g.edges = append(g.edges, OwnershipEdge{
+			Owner:      ownerUID,
+			Dependent:  uid,
+			Controller: ref.Controller != nil && *ref.Controller,
+		})
+	}
+}
+
+// hasCollectedOwner returns true if any owner of the given object is already collected.
+func (g *ownershipGraph) hasCollectedOwner(obj metav1.Object) bool {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	for _, ref := range obj.GetOwnerReferences() {
+		if node, ok := g.nodes[string(ref.UID)]; ok && node.Collected {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCollected returns true if the object with given UID is collected.
+func (g *ownershipGraph) isCollected(uid types.UID) bool {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	node, ok := g.nodes[string(uid)]
+
+	return ok && node.Collected
+}
+
+func (g *ownershipGraph) graph() *OwnershipGraph {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	graph := &OwnershipGraph{
+		Nodes: make([]OwnershipNode, 0, len(g.nodes)),
+		Edges: append([]OwnershipEdge{}, g.edges...),
+	}
+
+	for _, node := range g.nodes {
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].UID < graph.Nodes[j].UID
+	})
+
+	// Objects are recorded concurrently, so sort edges too for a stable output
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Owner != graph.Edges[j].Owner {
+			return graph.Edges[i].Owner < graph.Edges[j].Owner
+		}
+
+		return graph.Edges[i].Dependent < graph.Edges[j].Dependent
+	})
+
+	return graph
+}
+
+// writeOwnershipGraph writes the ownership graph in JSON and graphviz dot format.
+func writeOwnershipGraph(state *collectionState, rootOutputPath string) error {
+	graph := state.ownerGraph.graph()
+
+	data, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := populateScraperDir(data, filepath.Join(rootOutputPath, OwnershipJSONFile)); err != nil {
+		return err
+	}
+
+	var dot strings.Builder
+
+	dot.WriteString("digraph ownership {\n")
+
+	for idx := range graph.Nodes {
+		node := &graph.Nodes[idx]
+		style := ""
+
+		if !node.Collected {
+			style = ", style=dashed"
+		}
+
+		fmt.Fprintf(&dot, "  %q [label=%q%s];\n", node.UID,
+			node.Kind+"\n"+filepath.Join(node.Namespace, node.Name), style)
+	}
+
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&dot, "  %q -> %q;\n", edge.Owner, edge.Dependent)
+	}
+
+	dot.WriteString("}\n")
+
+	return populateScraperDir([]byte(dot.String()), filepath.Join(rootOutputPath, OwnershipDotFile))
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const PDBStatusFile = "pdb_status.txt"
+
+// pdbPod is a collected pod, matched against the selectors of the PodDisruptionBudgets of its namespace.
+type pdbPod struct {
+	labels map[string]string
+	name   string
+	ready  bool
+}
+
+// pdbState records the collected PodDisruptionBudgets and pods of each namespace, used to show the pods covered
+// by each budget along with its status.
+type pdbState struct {
+	budgets map[string][]*policyv1.PodDisruptionBudget
+	pods    map[string][]pdbPod
+	mutex   sync.Mutex
+}
+
+func newPDBState() *pdbState {
+	return &pdbState{budgets: map[string][]*policyv1.PodDisruptionBudget{}, pods: map[string][]pdbPod{}}
+}
+
+// recordBudget records the given PodDisruptionBudget.
+func (s *pdbState) recordBudget(obj *unstructured.Unstructured) error {
+	pdb := &policyv1.PodDisruptionBudget{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pdb); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.budgets[pdb.Namespace] = append(s.budgets[pdb.Namespace], pdb)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// recordPod records the labels and readiness of the given pod.
+func (s *pdbState) recordPod(pod *corev1.Pod) {
+	s.mutex.Lock()
+	s.pods[pod.Namespace] = append(s.pods[pod.Namespace], pdbPod{labels: pod.Labels, name: pod.Name,
+		ready: isPodReady(pod)})
+	s.mutex.Unlock()
+}
+
+// write writes PDBStatusFile in the PodDisruptionBudget directory of the given namespace, if any budgets are
+// collected in it.
+func (s *pdbState) write(ns, rootOutputPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.budgets[ns]) == 0 {
+		return nil
+	}
+
+	pdbDir := filepath.Join(rootOutputPath, KindDirNames[internal.PDBKind])
+	if err := os.MkdirAll(pdbDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	return populateScraperDir(formatPDBStatus(s.budgets[ns], s.pods[ns]), filepath.Join(pdbDir, PDBStatusFile))
+}
+
+// formatPDBStatus formats the status of the given PodDisruptionBudgets in a table, followed by the collected pods
+// covered by each of them. Pods which are not ready are not counted in currentHealthy.
+func formatPDBStatus(budgets []*policyv1.PodDisruptionBudget, pods []pdbPod) []byte {
+	var buf bytes.Buffer
+
+	sorted := make([]*policyv1.PodDisruptionBudget, len(budgets))
+	copy(sorted, budgets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	covered := make([][]pdbPod, len(sorted))
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PDB\tMIN AVAILABLE\tMAX UNAVAILABLE\tCURRENT HEALTHY\tDESIRED HEALTHY\tEXPECTED PODS\t"+
+		"DISRUPTIONS ALLOWED\tCOLLECTED PODS")
+
+	for idx, pdb := range sorted {
+		covered[idx] = pdbCoveredPods(pdb, pods)
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\n", pdb.Name, intOrStringOrNone(pdb.Spec.MinAvailable),
+			intOrStringOrNone(pdb.Spec.MaxUnavailable), pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy,
+			pdb.Status.ExpectedPods, pdb.Status.DisruptionsAllowed, len(covered[idx]))
+	}
+
+	_ = w.Flush()
+
+	for idx, pdb := range sorted {
+		fmt.Fprintf(&buf, "\nPodDisruptionBudget: %s\n", pdb.Name)
+
+		if len(covered[idx]) == 0 {
+			fmt.Fprintln(&buf, "  No collected pods")
+			continue
+		}
+
+		w = tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "  POD\tREADY\tDISRUPTED")
+
+		for _, pod := range covered[idx] {
+			_, disrupted := pdb.Status.DisruptedPods[pod.name]
+			fmt.Fprintf(w, "  %s\t%t\t%t\n", pod.name, pod.ready, disrupted)
+		}
+
+		_ = w.Flush()
+	}
+
+	return buf.Bytes()
+}
+
+// pdbCoveredPods returns the given pods matching the selector of the given PodDisruptionBudget, sorted by name.
+// A budget with an empty selector covers all the pods of its namespace, and one without a selector covers none.
+func pdbCoveredPods(pdb *policyv1.PodDisruptionBudget, pods []pdbPod) []pdbPod {
+	if pdb.Spec.Selector == nil {
+		return nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		return nil
+	}
+
+	var covered []pdbPod
+
+	for _, pod := range pods {
+		if selector.Matches(labels.Set(pod.labels)) {
+			covered = append(covered, pod)
+		}
+	}
+
+	sort.Slice(covered, func(i, j int) bool { return covered[i].name < covered[j].name })
+
+	return covered
+}
+
+func intOrStringOrNone(value *intstr.IntOrString) string {
+	if value == nil {
+		return "<none>"
+	}
+
+	return value.String()
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// LogSuffix is the suffix of the captured log files
+	LogSuffix = ".log"
+	// GzipFileSuffix is appended to the log files gzipped individually
+	GzipFileSuffix = ".gz"
+)
+
+// gzipLargeLogs gzips each log file in the given bundle directory bigger than threshold bytes into a .log.gz file,
+// so that big logs can be extracted on their own. The akoctl.log of the bundle is still being written, so it is
+// kept as it is.
+func gzipLargeLogs(bundleDir string, threshold int64) error {
+	return filepath.WalkDir(bundleDir, func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(file, LogSuffix) || file == filepath.Join(bundleDir, LogFileName) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.Size() <= threshold {
+			return nil
+		}
+
+		if err := gzipFile(file); err != nil {
+			return err
+		}
+
+		return os.Remove(file)
+	})
+}
+
+// gzipFile writes the gzipped content of the given file in the file with GzipFileSuffix.
+func gzipFile(file string) error {
+	src, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filepath.Clean(file+GzipFileSuffix), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const PodNetworkFile = "pod_network.txt"
+
+// cniAnnotationPrefixes are the annotation prefixes of the common CNI plugins and Multus, recording the networks
+// and the IPs assigned to a pod by its sandbox.
+var cniAnnotationPrefixes = []string{
+	"k8s.v1.cni.cncf.io/",
+	"v1.multus-cni.io/",
+	"cni.projectcalico.org/",
+	"k8s.ovn.org/",
+	"ovn.kubernetes.io/",
+	"vpc.amazonaws.com/",
+	"networking.gke.io/",
+	"kubernetes.azure.com/",
+}
+
+// formatPodNetwork formats the IPs, host IP and node of the given pods in a table, along with their CNI annotations.
+func formatPodNetwork(pods []*corev1.Pod) []byte {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "POD\tPOD IPS\tHOST IP\tNODE\tCNI ANNOTATIONS")
+
+	for _, pod := range pods {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", pod.Name, valueOrNone(podIPs(pod)), valueOrNone(pod.Status.HostIP),
+			valueOrNone(pod.Spec.NodeName), valueOrNone(cniAnnotations(pod)))
+	}
+
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
+
+// podIPs returns the IPs assigned to the given pod, which has the primary IP first for dual-stack pods.
+func podIPs(pod *corev1.Pod) string {
+	if len(pod.Status.PodIPs) == 0 {
+		return pod.Status.PodIP
+	}
+
+	ips := make([]string, 0, len(pod.Status.PodIPs))
+
+	for idx := range pod.Status.PodIPs {
+		ips = append(ips, pod.Status.PodIPs[idx].IP)
+	}
+
+	return strings.Join(ips, ",")
+}
+
+// cniAnnotations returns the CNI annotations of the given pod, with the whitespace in multi-line values such as the
+// Multus network status collapsed to keep a row per pod.
+func cniAnnotations(pod *corev1.Pod) string {
+	var items []string
+
+	for key, value := range pod.Annotations {
+		for _, prefix := range cniAnnotationPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				items = append(items, key+"="+strings.Join(strings.Fields(value), " "))
+				break
+			}
+		}
+	}
+
+	sort.Strings(items)
+
+	return strings.Join(items, "; ")
+}
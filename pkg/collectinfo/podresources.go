@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	ResourcesFile       = "resources.txt"
+	NodeAllocatableFile = "node_allocatable.txt"
+)
+
+// nodeResourceState records the collected nodes and the requests of the collected pods scheduled on them, used to
+// compare the requests against the node allocatable.
+type nodeResourceState struct {
+	requested map[string]corev1.ResourceList
+	nodes     []*corev1.Node
+	mutex     sync.Mutex
+}
+
+func newNodeResourceState() *nodeResourceState {
+	return &nodeResourceState{
+		requested: map[string]corev1.ResourceList{},
+	}
+}
+
+// recordNode records the given collected node.
+func (s *nodeResourceState) recordNode(obj *unstructured.Unstructured) error {
+	node := &corev1.Node{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, node); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.nodes = append(s.nodes, node)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// recordPod adds the requests of the given collected pod to its node.
+func (s *nodeResourceState) recordPod(pod *corev1.Pod) {
+	if pod.Spec.NodeName == "" {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	requested, ok := s.requested[pod.Spec.NodeName]
+	if !ok {
+		requested = corev1.ResourceList{}
+		s.requested[pod.Spec.NodeName] = requested
+	}
+
+	for name, quantity := range podRequests(pod) {
+		total := requested[name]
+		total.Add(quantity)
+		requested[name] = total
+	}
+}
+
+// writeAllocatable writes NodeAllocatableFile with the capacity and allocatable of the collected nodes, along with
+// the requests of the collected pods on each node. It is not written if no node is collected.
+func (s *nodeResourceState) writeAllocatable(objOutputDir string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.nodes) == 0 {
+		return nil
+	}
+
+	sort.Slice(s.nodes, func(i, j int) bool {
+		return s.nodes[i].Name < s.nodes[j].Name
+	})
+
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tCPU CAPACITY\tCPU ALLOCATABLE\tCPU REQUESTED\tMEMORY CAPACITY\tMEMORY ALLOCATABLE\t"+
+		"MEMORY REQUESTED\tPODS ALLOCATABLE")
+
+	for _, node := range s.nodes {
+		requested := s.requested[node.Name]
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", node.Name,
+			quantityOrNone(node.Status.Capacity, corev1.ResourceCPU),
+			quantityOrNone(node.Status.Allocatable, corev1.ResourceCPU),
+			quantityOrNone(requested, corev1.ResourceCPU),
+			quantityOrNone(node.Status.Capacity, corev1.ResourceMemory),
+			quantityOrNone(node.Status.Allocatable, corev1.ResourceMemory),
+			quantityOrNone(requested, corev1.ResourceMemory),
+			quantityOrNone(node.Status.Allocatable, corev1.ResourcePods))
+	}
+
+	_ = w.Flush()
+
+	return populateScraperDir(buf.Bytes(), filepath.Join(objOutputDir, NodeAllocatableFile))
+}
+
+// formatPodResources formats the requests and limits of each container of the given pods in a table.
+func formatPodResources(pods []*corev1.Pod) []byte {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "POD\tCONTAINER\tTYPE\tNODE\tCPU REQUEST\tCPU LIMIT\tMEMORY REQUEST\tMEMORY LIMIT")
+
+	for _, pod := range pods {
+		node := valueOrNone(pod.Spec.NodeName)
+
+		writeRow := func(container *corev1.Container, containerType string) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", pod.Name, container.Name, containerType, node,
+				quantityOrNone(container.Resources.Requests, corev1.ResourceCPU),
+				quantityOrNone(container.Resources.Limits, corev1.ResourceCPU),
+				quantityOrNone(container.Resources.Requests, corev1.ResourceMemory),
+				quantityOrNone(container.Resources.Limits, corev1.ResourceMemory))
+		}
+
+		for idx := range pod.Spec.InitContainers {
+			writeRow(&pod.Spec.InitContainers[idx], "init")
+		}
+
+		for idx := range pod.Spec.Containers {
+			writeRow(&pod.Spec.Containers[idx], "regular")
+		}
+	}
+
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"go.uber.org/zap"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// optionalKinds are skipped by the collection if they are not served by the cluster.
+var optionalKinds = sets.New(internal.BackupServiceKind, internal.GatewayKind, internal.HTTPRouteKind)
+
+// PreflightCheck is the result of a single access check performed by preflight.
+type PreflightCheck struct {
+	Namespace string
+	Resource  string
+	Verb      string
+	Reason    string
+	Allowed   bool
+}
+
+// RunPreflight verifies that the cluster is reachable and the current user can access everything collected
+// by collectinfo, without collecting anything. The checks are written as a readiness table to the given writer.
+// An error is returned only if the cluster is not reachable.
+func RunPreflight(ctx context.Context, params *configuration.Parameters, w io.Writer) ([]PreflightCheck, error) {
+	serverVersion, err := params.ClientSet.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("cluster is not reachable: %v", err)
+	}
+
+	params.Logger.Info("Connected to cluster", zap.String("server version", serverVersion.GitVersion))
+
+	var checks []PreflightCheck
+
+	if params.NamespaceScoped() {
+		namespacedGVKs := append([]schema.GroupVersionKind{
+			corev1.SchemeGroupVersion.WithKind(internal.EventKind),
+			corev1.SchemeGroupVersion.WithKind(internal.ConfigMapKind),
+			corev1.SchemeGroupVersion.WithKind(internal.ServiceAccountKind),
+			backupServiceGVK,
+		}, nsScopedGVKs(params)...)
+
+		for _, ns := range sets.List(params.Namespaces) {
+			for _, gvk := range namespacedGVKs {
+				checks = append(checks, checkKindAccess(ctx, params, gvk, ns))
+			}
+
+			checks = append(checks,
+				checkAccess(ctx, params, ns, "", "pods", "log", "get"),
+				checkList(ctx, params, ns))
+
+			if params.ExecConfig || params.Asinfo {
+				checks = append(checks, checkAccess(ctx, params, ns, "", "pods", "exec", "create"))
+			}
+
+			if params.AsinfoSecret != "" {
+				checks = append(checks, checkAccess(ctx, params, ns, "", "secrets", "", "get"))
+			}
+
+			if params.OperatorMetrics {
+				checks = append(checks, checkAccess(ctx, params, ns, "", "pods", "proxy", "get"))
+			}
+		}
+	}
+
+	if params.ClusterScoped() {
+		for _, gvk := range clusterScopedGVKs(params) {
+			checks = append(checks, checkKindAccess(ctx, params, gvk, ""))
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tRESOURCE\tVERB\tALLOWED\tREASON")
+
+	for idx := range checks {
+		namespace := checks[idx].Namespace
+		if namespace == "" {
+			namespace = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\n", namespace, checks[idx].Resource, checks[idx].Verb,
+			checks[idx].Allowed, checks[idx].Reason)
+	}
+
+	return checks, tw.Flush()
+}
+
+// checkKindAccess checks the list permission of the given kind using its resource name served by the cluster.
+func checkKindAccess(ctx context.Context, params *configuration.Parameters, gvk schema.GroupVersionKind,
+	ns string) PreflightCheck {
+	mapping, err := params.K8sClient.RESTMapper().RESTMapping(gvk.GroupKind())
+	if err != nil {
+		check := PreflightCheck{Namespace: ns, Resource: gvk.Kind, Verb: "list", Reason: err.Error()}
+
+		if meta.IsNoMatchError(err) {
+			check.Reason = "kind not served by the cluster"
+			if optionalKinds.Has(gvk.Kind) {
+				check.Allowed = true
+				check.Reason += ", skipped"
+			}
+		}
+
+		return check
+	}
+
+	return checkAccess(ctx, params, ns, mapping.Resource.Group, mapping.Resource.Resource, "", "list")
+}
+
+// checkAccess checks the given permission of the current user using SelfSubjectAccessReview.
+func checkAccess(ctx context.Context, params *configuration.Parameters, ns, group, resource, subresource,
+	verb string) PreflightCheck {
+	check := PreflightCheck{Namespace: ns, Resource: resource, Verb: verb}
+
+	if subresource != "" {
+		check.Resource += "/" + subresource
+	}
+
+	if group != "" {
+		check.Resource += "." + group
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   ns,
+				Verb:        verb,
+				Group:       group,
+				Resource:    resource,
+				Subresource: subresource,
+			},
+		},
+	}
+
+	result, err := params.ClientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review,
+		metav1.CreateOptions{})
+	if err != nil {
+		check.Reason = err.Error()
+		return check
+	}
+
+	check.Allowed = result.Status.Allowed
+	check.Reason = result.Status.Reason
+
+	if !check.Allowed && check.Reason == "" {
+		check.Reason = "forbidden"
+	}
+
+	return check
+}
+
+// checkList performs a trivial list request to verify that the namespace is actually accessible.
+func checkList(ctx context.Context, params *configuration.Parameters, ns string) PreflightCheck {
+	check := PreflightCheck{Namespace: ns, Resource: "pods", Verb: "list (request)", Allowed: true}
+
+	if _, err := params.ClientSet.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		check.Allowed = false
+		check.Reason = err.Error()
+	}
+
+	return check
+}
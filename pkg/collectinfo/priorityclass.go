@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// recordPriorityClass adds the PriorityClass of the given name, referenced by a pod, to the PriorityClass
+// selection set.
+func recordPriorityClass(state *collectionState, name string) {
+	if name == "" {
+		return
+	}
+
+	state.selectionMutex.Lock()
+	state.priorityClassNameSet.Insert(name)
+	state.selectionMutex.Unlock()
+}
+
+// listPriorityClassNames adds PriorityClasses referenced by pods in the given namespace to the PriorityClass
+// selection set.
+func listPriorityClassNames(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	ns string) error {
+	pods, err := params.ClientSet.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		params.Logger.Error("Not able to list ", zap.String("kind", internal.PodKind), zap.Error(err))
+		return err
+	}
+
+	for idx := range pods.Items {
+		if params.ClusterName != "" && !isClusterObject(params, state, internal.PodKind, &pods.Items[idx]) {
+			continue
+		}
+
+		recordPriorityClass(state, pods.Items[idx].Spec.PriorityClassName)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const ProbesFile = "probes.txt"
+
+// probeEventReasons are the reasons of the kubelet events of failed or misconfigured probes
+var probeEventReasons = sets.New("Unhealthy", "ProbeWarning")
+
+// maxProbeEvents is the number of latest probe events kept per pod
+const maxProbeEvents = 10
+
+// formatProbes formats the liveness, readiness and startup probes of the containers of the given Aerospike pod and
+// its latest probe events. It returns nil if the pod has neither probes nor probe events.
+func formatProbes(pod *corev1.Pod, events []corev1.Event) []byte {
+	if _, ok := pod.Labels[ClusterNameLabel]; !ok {
+		return nil
+	}
+
+	var (
+		buf         bytes.Buffer
+		probeEvents []corev1.Event
+	)
+
+	for idx := range events {
+		if probeEventReasons.Has(events[idx].Reason) {
+			probeEvents = append(probeEvents, events[idx])
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+
+	for idx := range containers {
+		container := &containers[idx]
+		if container.LivenessProbe == nil && container.ReadinessProbe == nil && container.StartupProbe == nil {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "Container: %s\n", container.Name)
+		fmt.Fprintf(&buf, "  Liveness: %s\n", describeProbe(container.LivenessProbe))
+		fmt.Fprintf(&buf, "  Readiness: %s\n", describeProbe(container.ReadinessProbe))
+		fmt.Fprintf(&buf, "  Startup: %s\n\n", describeProbe(container.StartupProbe))
+	}
+
+	if buf.Len() == 0 && len(probeEvents) == 0 {
+		return nil
+	}
+
+	if len(probeEvents) == 0 {
+		buf.WriteString("Probe events: <none>\n")
+		return buf.Bytes()
+	}
+
+	// events are sorted oldest first, so the latest are at the end
+	probeTable := formatEvents(probeEvents)
+	lines := strings.SplitAfter(strings.TrimSuffix(string(probeTable), "\n"), "\n")
+
+	if len(lines) > maxProbeEvents+1 {
+		lines = append(lines[:1], lines[len(lines)-maxProbeEvents:]...)
+	}
+
+	buf.WriteString("Probe events:\n")
+	buf.WriteString(strings.Join(lines, "") + "\n")
+
+	return buf.Bytes()
+}
+
+// describeProbe describes the given probe like kubectl describe pod.
+func describeProbe(probe *corev1.Probe) string {
+	if probe == nil {
+		return valueOrNone("")
+	}
+
+	attrs := fmt.Sprintf("delay=%ds timeout=%ds period=%ds #success=%d #failure=%d", probe.InitialDelaySeconds,
+		probe.TimeoutSeconds, probe.PeriodSeconds, probe.SuccessThreshold, probe.FailureThreshold)
+
+	switch {
+	case probe.Exec != nil:
+		return fmt.Sprintf("exec %v %s", probe.Exec.Command, attrs)
+	case probe.HTTPGet != nil:
+		host := net.JoinHostPort(probe.HTTPGet.Host, probe.HTTPGet.Port.String())
+		scheme := strings.ToLower(string(probe.HTTPGet.Scheme))
+
+		if scheme == "" {
+			scheme = "http"
+		}
+
+		return fmt.Sprintf("http-get %s://%s%s %s", scheme, host, probe.HTTPGet.Path, attrs)
+	case probe.TCPSocket != nil:
+		return fmt.Sprintf("tcp-socket %s %s", net.JoinHostPort(probe.TCPSocket.Host,
+			probe.TCPSocket.Port.String()), attrs)
+	case probe.GRPC != nil:
+		return fmt.Sprintf("grpc <pod>:%d %s %s", probe.GRPC.Port, valueOrNone(stringValue(probe.GRPC.Service)),
+			attrs)
+	default:
+		return fmt.Sprintf("unknown %s", attrs)
+	}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const PullErrorsFile = "pull_errors.txt"
+
+// imagePullReasons are the waiting reasons of containers whose image can not be pulled.
+var imagePullReasons = sets.New("ImagePullBackOff", "ErrImagePull", "InvalidImageName")
+
+// pullError is a container stuck pulling its image.
+type pullError struct {
+	pod       string
+	container string
+	image     string
+	node      string
+	reason    string
+	message   string
+}
+
+// allContainerStatuses returns the statuses of init, regular and ephemeral containers of the given pod.
+func allContainerStatuses(pod *corev1.Pod) []corev1.ContainerStatus {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...),
+		pod.Status.ContainerStatuses...)
+
+	return append(statuses, pod.Status.EphemeralContainerStatuses...)
+}
+
+// podPullErrors returns the containers of the given pod which are waiting on image pull.
+func podPullErrors(pod *corev1.Pod) []pullError {
+	var pullErrors []pullError
+
+	statuses := allContainerStatuses(pod)
+
+	for idx := range statuses {
+		waiting := statuses[idx].State.Waiting
+		if waiting == nil || !imagePullReasons.Has(waiting.Reason) {
+			continue
+		}
+
+		pullErrors = append(pullErrors, pullError{
+			pod:       pod.Name,
+			container: statuses[idx].Name,
+			image:     statuses[idx].Image,
+			node:      pod.Spec.NodeName,
+			reason:    waiting.Reason,
+			message:   strings.TrimSpace(waiting.Message),
+		})
+	}
+
+	return pullErrors
+}
+
+// formatPullErrors formats image pull errors in a table.
+func formatPullErrors(pullErrors []pullError) []byte {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "POD\tCONTAINER\tIMAGE\tNODE\tREASON\tMESSAGE")
+
+	for idx := range pullErrors {
+		pullErr := &pullErrors[idx]
+
+		node := pullErr.node
+		if node == "" {
+			node = "<none>"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", pullErr.pod, pullErr.container, pullErr.image, node,
+			pullErr.reason, pullErr.message)
+	}
+
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// RedactedValue replaces the value of each redacted field.
+const RedactedValue = "<redacted>"
+
+// pathSegment is a field of a redaction path, indexing a list if index is set, where -1 is all of its items.
+type pathSegment struct {
+	field    string
+	index    int
+	hasIndex bool
+}
+
+// defaultRedactionRules mask the environment variable values of the Aerospike and operator containers, which
+// often carry credentials, and the values of the referenced Secrets, along with their annotations which may have
+// the last applied data. These are skipped with params.NoDefaultRedaction.
+var defaultRedactionRules = []configuration.RedactionRule{
+	{
+		Kind: internal.PodKind,
+		Paths: []string{
+			"spec.containers[*].env[*].value",
+			"spec.initContainers[*].env[*].value",
+		},
+	},
+	{
+		Kind: internal.STSKind,
+		Paths: []string{
+			"spec.template.spec.containers[*].env[*].value",
+			"spec.template.spec.initContainers[*].env[*].value",
+		},
+	},
+	{
+		Kind: internal.DeployKind,
+		Paths: []string{
+			"spec.template.spec.containers[*].env[*].value",
+			"spec.template.spec.initContainers[*].env[*].value",
+		},
+	},
+	{
+		Kind: internal.AerospikeClusterKind,
+		Paths: []string{
+			"spec.podSpec.sidecars[*].env[*].value",
+			"spec.podSpec.initContainers[*].env[*].value",
+		},
+	},
+	{
+		Kind: internal.SecretKind,
+		Paths: []string{
+			"data.*",
+			"stringData.*",
+			"metadata.annotations.*",
+		},
+	},
+}
+
+// parseRedactionRules returns the parsed paths of each kind of the default rules, unless skipped, and the rules
+// of params. Invalid rules and paths of params are skipped with a warning.
+func parseRedactionRules(params *configuration.Parameters) map[string][][]pathSegment {
+	parsed := map[string][][]pathSegment{}
+
+	if !params.NoDefaultRedaction {
+		for idx := range defaultRedactionRules {
+			for _, path := range defaultRedactionRules[idx].Paths {
+				segments, err := parseRedactionPath(path)
+				if err != nil {
+					panic(fmt.Sprintf("invalid default redaction path %q: %v", path, err))
+				}
+
+				parsed[defaultRedactionRules[idx].Kind] = append(parsed[defaultRedactionRules[idx].Kind], segments)
+			}
+		}
+	}
+
+	for idx := range params.RedactionRules {
+		rule := &params.RedactionRules[idx]
+		if rule.Kind == "" {
+			params.Logger.Warn("Redaction rule without kind, skipping it", zap.Strings("paths", rule.Paths))
+			continue
+		}
+
+		for _, path := range rule.Paths {
+			segments, err := parseRedactionPath(path)
+			if err != nil {
+				params.Logger.Warn("Invalid redaction path, skipping it", zap.String("kind", rule.Kind),
+					zap.String("path", path), zap.Error(err))
+
+				continue
+			}
+
+			parsed[rule.Kind] = append(parsed[rule.Kind], segments)
+		}
+	}
+
+	return parsed
+}
+
+// parseRedactionPath parses the given path, optionally prefixed with $. as in JSONPath.
+func parseRedactionPath(path string) ([]pathSegment, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	fields := strings.Split(path, ".")
+	segments := make([]pathSegment, 0, len(fields))
+
+	for _, field := range fields {
+		segment := pathSegment{field: field}
+
+		if open := strings.Index(field, "["); open >= 0 {
+			if !strings.HasSuffix(field, "]") {
+				return nil, fmt.Errorf("unterminated index in %q", field)
+			}
+
+			segment.field, segment.hasIndex = field[:open], true
+
+			switch index := field[open+1 : len(field)-1]; index {
+			case "*":
+				segment.index = -1
+			default:
+				parsed, err := strconv.Atoi(index)
+				if err != nil || parsed < 0 {
+					return nil, fmt.Errorf("invalid index %q in %q", index, field)
+				}
+
+				segment.index = parsed
+			}
+		}
+
+		if segment.field == "" || strings.ContainsAny(segment.field, "[]") {
+			return nil, fmt.Errorf("invalid field %q", field)
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
+
+// redactPod returns the given pod with its fields masked as per the redaction rules of pods. The pod is converted
+// to unstructured to be redacted, so that the pod itself is left as is for the summaries written after it.
+func redactPod(state *collectionState, pod *corev1.Pod) (interface{}, error) {
+	if len(state.redactionRules[internal.PodKind]) == 0 {
+		return pod, nil
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	redactObject(state, internal.PodKind, obj)
+
+	return obj, nil
+}
+
+// redactObject masks the fields of the given object matching the redaction rules of its kind. Paths not present
+// in the object are ignored.
+func redactObject(state *collectionState, kind string, obj map[string]interface{}) {
+	for _, segments := range state.redactionRules[kind] {
+		redactPath(obj, segments)
+	}
+}
+
+func redactPath(obj map[string]interface{}, segments []pathSegment) {
+	segment := segments[0]
+
+	var keys []string
+
+	if segment.field == "*" {
+		for key := range obj {
+			keys = append(keys, key)
+		}
+	} else if _, ok := obj[segment.field]; ok {
+		keys = append(keys, segment.field)
+	}
+
+	for _, key := range keys {
+		if !segment.hasIndex {
+			obj[key] = redactValue(obj[key], segments[1:])
+			continue
+		}
+
+		list, ok := obj[key].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for idx := range list {
+			if segment.index == -1 || segment.index == idx {
+				list[idx] = redactValue(list[idx], segments[1:])
+			}
+		}
+	}
+}
+
+// redactValue returns RedactedValue if the path is fully matched, otherwise redacts the rest of the path in the
+// given value.
+func redactValue(value interface{}, segments []pathSegment) interface{} {
+	if len(segments) == 0 {
+		return RedactedValue
+	}
+
+	if nested, ok := value.(map[string]interface{}); ok {
+		redactPath(nested, segments)
+	}
+
+	return value
+}
+
+// redactLogs masks the substrings of the given logs matching the given patterns with RedactedValue. If a pattern has
+// capture groups, only the captured substrings are masked, so that for example the key of a password=value pair is
+// kept for context.
+func redactLogs(logs []byte, patterns []*regexp.Regexp) []byte {
+	for _, re := range patterns {
+		if re.NumSubexp() == 0 {
+			logs = re.ReplaceAllLiteral(logs, []byte(RedactedValue))
+			continue
+		}
+
+		matches := re.FindAllSubmatchIndex(logs, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var (
+			redacted []byte
+			last     int
+		)
+
+		for _, match := range matches {
+			for group := 1; group <= re.NumSubexp(); group++ {
+				start, end := match[2*group], match[2*group+1]
+				// groups which are not matched or overlap the masked ones are skipped
+				if start < last {
+					continue
+				}
+
+				redacted = append(redacted, logs[last:start]...)
+				redacted = append(redacted, RedactedValue...)
+				last = end
+			}
+		}
+
+		logs = append(redacted, logs[last:]...)
+	}
+
+	return logs
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/version"
+)
+
+const ReportFile = "collection_report.json"
+
+// SkippedKind is a kind which is not collected, along with the reason.
+type SkippedKind struct {
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+// CollectionReport records when and how a bundle was collected.
+type CollectionReport struct {
+	StartTime      time.Time         `json:"startTime"`
+	EndTime        time.Time         `json:"endTime"`
+	FieldSelectors map[string]string `json:"fieldSelectors,omitempty"`
+	Duration       string            `json:"duration"`
+	FollowDuration string            `json:"followDuration,omitempty"`
+	ContextTimeout string            `json:"contextTimeout,omitempty"`
+	ChangedSince   string            `json:"changedSince,omitempty"`
+	Interval       string            `json:"interval,omitempty"`
+	PrometheusURL  string            `json:"prometheusURL,omitempty"`
+	PromRange      string            `json:"prometheusRange,omitempty"`
+	MaxNsLogBytes  int64             `json:"maxNamespaceLogBytes,omitempty"`
+	PageSize       int64             `json:"pageSize,omitempty"`
+	DeltaObjects   int               `json:"deltaObjects,omitempty"`
+	Version        string            `json:"akoctlVersion"`
+	Context        string            `json:"context,omitempty"`
+	Scope          string            `json:"scope"`
+	NamePrefix     string            `json:"namePrefix,omitempty"`
+	NamespaceRegex string            `json:"namespaceRegex,omitempty"`
+	LogGrep        string            `json:"logGrep,omitempty"`
+	ResourceVer    string            `json:"resourceVersion,omitempty"`
+	ClusterName    string            `json:"clusterName,omitempty"`
+	AppendTo       string            `json:"appendTo,omitempty"`
+	Baseline       string            `json:"baseline,omitempty"`
+	NodeSelector   string            `json:"nodeSelector,omitempty"`
+	LogSelector    string            `json:"logSelector,omitempty"`
+	OperatorSel    string            `json:"operatorSelector,omitempty"`
+	TargetNode     string            `json:"node,omitempty"`
+	NameTemplate   string            `json:"outputNameTemplate,omitempty"`
+	Namespaces     []string          `json:"namespaces"`
+	SkippedKinds   []SkippedKind     `json:"skippedKinds,omitempty"`
+	MissingKinds   []string          `json:"missingKinds,omitempty"`
+	AsinfoCmds     []string          `json:"asinfoCommands,omitempty"`
+	RedactLogs     []string          `json:"redactLogs,omitempty"`
+	Failures       []CaptureFailure  `json:"failures,omitempty"`
+	AllNamespaces  bool              `json:"allNamespaces"`
+	Consolidate    bool              `json:"consolidate"`
+	SingleJSON     bool              `json:"singleJSON,omitempty"`
+	ParallelComp   bool              `json:"parallelCompress,omitempty"`
+	PerFileGzip    bool              `json:"perFileGzip,omitempty"`
+	PerNsArchive   bool              `json:"perNamespaceArchive,omitempty"`
+	LogTimestamps  bool              `json:"logTimestamps,omitempty"`
+	SinceRestart   bool              `json:"sinceRestart,omitempty"`
+	ExecConfig     bool              `json:"execConfig,omitempty"`
+	Asinfo         bool              `json:"asinfo,omitempty"`
+	GatewayAPI     bool              `json:"gatewayAPI,omitempty"`
+	Autoscaler     bool              `json:"autoscaler,omitempty"`
+	OperatorMetric bool              `json:"operatorMetrics,omitempty"`
+	Strict         bool              `json:"strict,omitempty"`
+	OnlyUnhealthy  bool              `json:"onlyIfUnhealthy,omitempty"`
+	KeepFullLogs   bool              `json:"keepFullLogs,omitempty"`
+	AerospikeNodes bool              `json:"aerospikeNodes,omitempty"`
+	ManagedFields  bool              `json:"keepManagedFields,omitempty"`
+	KubeSystem     bool              `json:"includeKubeSystem"`
+	Resume         bool              `json:"resume"`
+	Truncated      bool              `json:"truncated,omitempty"`
+}
+
+func newCollectionReport(params *configuration.Parameters, startTime time.Time) *CollectionReport {
+	scope := params.Scope
+	if scope == "" {
+		scope = configuration.ScopeNamespace
+		if params.ClusterScope {
+			scope = configuration.ScopeBoth
+		}
+	}
+
+	report := &CollectionReport{
+		StartTime:      startTime,
+		FieldSelectors: params.FieldSelectors,
+		Version:        version.Version,
+		Context:        params.ContextName,
+		Scope:          scope,
+		NamePrefix:     params.NamePrefix,
+		ResourceVer:    params.ResourceVersion,
+		ClusterName:    params.ClusterName,
+		AppendTo:       params.AppendTo,
+		Baseline:       params.Baseline,
+		NodeSelector:   params.NodeSelector,
+		LogSelector:    params.LogSelector,
+		OperatorSel:    params.OperatorSelector,
+		TargetNode:     params.TargetNode,
+		NameTemplate:   params.OutputNameTemplate,
+		Namespaces:     sets.List(params.Namespaces),
+		AllNamespaces:  params.AllNamespaces,
+		Consolidate:    params.Consolidate,
+		SingleJSON:     params.SingleJSON,
+		ParallelComp:   params.ParallelCompress,
+		PerFileGzip:    params.PerFileGzip,
+		PerNsArchive:   params.PerNamespaceArchive,
+		LogTimestamps:  params.LogTimestamps,
+		SinceRestart:   params.SinceRestart,
+		ExecConfig:     params.ExecConfig,
+		Asinfo:         params.Asinfo,
+		AsinfoCmds:     params.AsinfoCommands,
+		GatewayAPI:     params.GatewayAPI,
+		Autoscaler:     params.Autoscaler,
+		OperatorMetric: params.OperatorMetrics,
+		Strict:         params.Strict,
+		OnlyUnhealthy:  params.OnlyIfUnhealthy,
+		KeepFullLogs:   params.KeepFullLogs,
+		AerospikeNodes: params.AerospikeNodes,
+		ManagedFields:  params.KeepManagedFields,
+		KubeSystem:     params.IncludeKubeSystem,
+		Resume:         params.Resume,
+		MaxNsLogBytes:  params.MaxNamespaceLogBytes,
+		PageSize:       params.PageSize,
+	}
+
+	if params.NamespaceRegex != nil {
+		report.NamespaceRegex = params.NamespaceRegex.String()
+	}
+
+	if params.LogGrep != nil {
+		report.LogGrep = params.LogGrep.String()
+	}
+
+	for _, re := range params.LogRedactions {
+		report.RedactLogs = append(report.RedactLogs, re.String())
+	}
+
+	if params.FollowDuration > 0 {
+		report.FollowDuration = params.FollowDuration.String()
+	}
+
+	if params.ChangedSince > 0 {
+		report.ChangedSince = params.ChangedSince.String()
+	}
+
+	if params.Interval > 0 {
+		report.Interval = params.Interval.String()
+	}
+
+	if params.ContextTimeout > 0 {
+		report.ContextTimeout = params.ContextTimeout.String()
+	}
+
+	if params.PrometheusURL != "" {
+		report.PrometheusURL = params.PrometheusURL
+		report.PromRange = params.PrometheusRange.String()
+	}
+
+	return report
+}
+
+// skipKind records the kind skipped because of the given error.
+func (r *CollectionReport) skipKind(kind string, err error) {
+	r.SkippedKinds = append(r.SkippedKinds, SkippedKind{Kind: kind, Reason: err.Error()})
+}
+
+// write records the end time and writes the report in the given directory.
+func (r *CollectionReport) write(rootOutputPath string) error {
+	r.EndTime = time.Now()
+	r.Duration = r.EndTime.Sub(r.StartTime).String()
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return populateScraperDir(data, filepath.Join(rootOutputPath, ReportFile))
+}
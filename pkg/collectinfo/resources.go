@@ -19,44 +19,110 @@ import (
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	v1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
 	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
 )
 
 var (
 	KindDirNames = map[string]string{
-		internal.NodeKind:              "nodes",
-		internal.PVCKind:               "persistentvolumeclaims",
-		internal.PVKind:                "persistentvolumes",
-		internal.STSKind:               "statefulsets",
-		internal.DeployKind:            "deployments",
-		internal.SCKind:                "storageclasses",
-		internal.AerospikeClusterKind:  "aerospikeclusters",
-		internal.PodKind:               "pods",
-		internal.EventKind:             "events",
-		internal.MutatingWebhookKind:   "mutatingwebhookconfigurations",
-		internal.ValidatingWebhookKind: "validatingwebhookconfigurations",
-		internal.ServiceKind:           "services",
+		internal.NodeKind:               "nodes",
+		internal.PVCKind:                "persistentvolumeclaims",
+		internal.PVKind:                 "persistentvolumes",
+		internal.STSKind:                "statefulsets",
+		internal.DeployKind:             "deployments",
+		internal.SCKind:                 "storageclasses",
+		internal.AerospikeClusterKind:   "aerospikeclusters",
+		internal.PodKind:                "pods",
+		internal.EventKind:              "events",
+		internal.MutatingWebhookKind:    "mutatingwebhookconfigurations",
+		internal.ValidatingWebhookKind:  "validatingwebhookconfigurations",
+		internal.ServiceKind:            "services",
+		internal.ControllerRevisionKind: "controllerrevisions",
+		internal.ConfigMapKind:          "configmaps",
+		internal.SecretKind:             "secrets",
+		internal.BackupServiceKind:      "aerospikebackupservices",
+		internal.PDBKind:                "poddisruptionbudgets",
+		internal.RSKind:                 "replicasets",
+		internal.DaemonSetKind:          "daemonsets",
+		internal.PodTemplateKind:        "podtemplates",
+		internal.IngressKind:            "ingresses",
+		internal.GatewayKind:            "gateways",
+		internal.HTTPRouteKind:          "httproutes",
+		internal.VolumeAttachmentKind:   "volumeattachments",
+		internal.CSINodeKind:            "csinodes",
+		internal.CSIDriverKind:          "csidrivers",
+		internal.PriorityClassKind:      "priorityclasses",
+		internal.ServiceAccountKind:     "serviceaccounts",
 	}
 	gvkListNSScoped = []schema.GroupVersionKind{
 		{
-			Group:   "asdb.aerospike.com",
+			Group:   OperatorGroup,
 			Version: "v1",
 			Kind:    internal.AerospikeClusterKind,
 		},
 		appsv1.SchemeGroupVersion.WithKind(internal.STSKind),
 		appsv1.SchemeGroupVersion.WithKind(internal.DeployKind),
+		// ReplicaSets are owned by Deployments, so these are listed after Deployments
+		appsv1.SchemeGroupVersion.WithKind(internal.RSKind),
+		appsv1.SchemeGroupVersion.WithKind(internal.DaemonSetKind),
+		appsv1.SchemeGroupVersion.WithKind(internal.ControllerRevisionKind),
 		corev1.SchemeGroupVersion.WithKind(internal.PodKind),
 		corev1.SchemeGroupVersion.WithKind(internal.PVCKind),
 		corev1.SchemeGroupVersion.WithKind(internal.ServiceKind),
+		policyv1.SchemeGroupVersion.WithKind(internal.PDBKind),
+		corev1.SchemeGroupVersion.WithKind(internal.PodTemplateKind),
+		networkingv1.SchemeGroupVersion.WithKind(internal.IngressKind),
+	}
+	// gvkListGatewayAPI are the Gateway API kinds collected along with gvkListNSScoped if enabled in params
+	gvkListGatewayAPI = []schema.GroupVersionKind{
+		{Group: "gateway.networking.k8s.io", Version: "v1", Kind: internal.GatewayKind},
+		{Group: "gateway.networking.k8s.io", Version: "v1", Kind: internal.HTTPRouteKind},
 	}
 	gvkListClusterScoped = []schema.GroupVersionKind{
 		corev1.SchemeGroupVersion.WithKind(internal.NodeKind),
 		v1.SchemeGroupVersion.WithKind(internal.SCKind),
 		corev1.SchemeGroupVersion.WithKind(internal.PVKind),
+		// VolumeAttachments are selected using PVs, so these are listed after PVs
+		v1.SchemeGroupVersion.WithKind(internal.VolumeAttachmentKind),
+		v1.SchemeGroupVersion.WithKind(internal.CSINodeKind),
+		v1.SchemeGroupVersion.WithKind(internal.CSIDriverKind),
+		schedulingv1.SchemeGroupVersion.WithKind(internal.PriorityClassKind),
 		admissionv1.SchemeGroupVersion.WithKind(internal.MutatingWebhookKind),
 		admissionv1.SchemeGroupVersion.WithKind(internal.ValidatingWebhookKind),
 	}
+	// OperatorConfigMapPrefixes select the ConfigMaps of the operator, which contain its config and feature gates.
+	// These are always collected from the given namespaces, irrespective of other filters.
+	OperatorConfigMapPrefixes = []string{"aerospike-operator-", "aerospike-kubernetes-operator"}
+	// ownerSelectedKinds are collected only if their owner is collected, so they are listed after the other kinds
+	ownerSelectedKinds = sets.New(internal.ControllerRevisionKind)
+	// KubeSystemPodSelectors select DNS and CNI related pods from kube-system namespace
+	KubeSystemPodSelectors = []string{
+		"k8s-app in (kube-dns,coredns,kube-proxy,calico-node,calico-kube-controllers,cilium,aws-node,antrea-agent)",
+		"app in (flannel,kube-flannel)",
+		"name in (weave-net,cilium-operator)",
+	}
 )
+
+// nsScopedGVKs returns the namespace scoped kinds to be collected as per params, followed by the extra kinds.
+func nsScopedGVKs(params *configuration.Parameters) []schema.GroupVersionKind {
+	gvks := append(append([]schema.GroupVersionKind{}, gvkListNSScoped...),
+		extraGVKs(params, configuration.ScopeNamespace)...)
+	if !params.GatewayAPI {
+		return gvks
+	}
+
+	return append(gvks, gvkListGatewayAPI...)
+}
+
+// clusterScopedGVKs returns the cluster scoped kinds to be collected as per params, followed by the extra kinds.
+func clusterScopedGVKs(params *configuration.Parameters) []schema.GroupVersionKind {
+	return append(append([]schema.GroupVersionKind{}, gvkListClusterScoped...),
+		extraGVKs(params, configuration.ScopeCluster)...)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// ProgressFile records the collection units completed so far. Each line is a unit in the form
+// <namespace>/<kind> for namespace scoped units and cluster/<kind> for cluster scoped units.
+// It is removed before the output directory is archived.
+const ProgressFile = ".akoctl_progress"
+
+type progress struct {
+	// ctx stops the collection before the next unit once it is done
+	ctx       context.Context
+	completed sets.Set[string]
+	logger    *zap.Logger
+	fileName  string
+	mutex     sync.Mutex
+	resume    bool
+}
+
+func newProgress(ctx context.Context, logger *zap.Logger, rootOutputPath string, resume bool) (*progress, error) {
+	p := &progress{
+		ctx:       ctx,
+		completed: sets.Set[string]{},
+		logger:    logger,
+		fileName:  filepath.Join(rootOutputPath, ProgressFile),
+		resume:    resume,
+	}
+
+	if !resume {
+		return p, nil
+	}
+
+	data, err := os.ReadFile(p.fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return p, nil
+		}
+
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		if unit := strings.TrimSpace(scanner.Text()); unit != "" {
+			p.completed.Insert(unit)
+		}
+	}
+
+	logger.Info("Resuming collection", zap.Int("completed units", p.completed.Len()))
+
+	return p, nil
+}
+
+// run runs the capture function of the given unit and records it as completed.
+// In resume mode, units completed by a previous run are skipped. It is safe to call concurrently for different units.
+func (p *progress) run(unit string, capture func() error) error {
+	if p.isCompleted(unit) {
+		p.logger.Info("Skipping already collected unit", zap.String("unit", unit))
+		return nil
+	}
+
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := capture(); err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	file, err := os.OpenFile(p.fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.WriteString(unit + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+
+	p.completed.Insert(unit)
+
+	return file.Close()
+}
+
+// isCompleted returns true if the given unit is completed by the previous run being resumed.
+func (p *progress) isCompleted(unit string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.resume && p.completed.Has(unit)
+}
+
+// clean removes the progress file so that it is not part of the archive.
+func (p *progress) clean() error {
+	if err := os.Remove(p.fileName); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// recordResumedObjects records the objects of the given kind collected by the previous run being resumed, without
+// writing them again. They are listed and selected like when they were collected, so that the objects selected using
+// them, like ControllerRevisions of the collected StatefulSets, are the same as in an uninterrupted run.
+func recordResumedObjects(ctx context.Context, params *configuration.Parameters, state *collectionState,
+	gvk schema.GroupVersionKind, ns string) error {
+	params.Logger.Info("Recording objects of already collected unit", zap.String("kind", gvk.Kind),
+		zap.String("namespace", ns))
+
+	if gvk.Kind != internal.PodKind {
+		return collectObjects(ctx, params, state, gvk, ns, "", false)
+	}
+
+	pods, err := listPods(ctx, params, ns, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for idx := range pods.Items {
+		if !isCollectedPod(params, state, &pods.Items[idx]) {
+			continue
+		}
+
+		recordPod(state, &pods.Items[idx])
+		state.ownerGraph.record(internal.PodKind, &pods.Items[idx])
+		state.stuckDeletions.record(internal.PodKind, &pods.Items[idx])
+	}
+
+	return nil
+}
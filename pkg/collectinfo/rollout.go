@@ -0,0 +1,274 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const RolloutDiffFile = "rollout_diff.txt"
+
+// rolloutController is the desired pod template of a collected StatefulSet or Deployment.
+type rolloutController struct {
+	selector       labels.Selector
+	images         map[string]string
+	kind           string
+	name           string
+	templateHash   string
+	updateRevision string
+	replicas       int32
+}
+
+// rolloutPod is the actual state of a collected pod.
+type rolloutPod struct {
+	labels   map[string]string
+	images   map[string]string
+	name     string
+	revision string
+	ready    bool
+}
+
+// rolloutState records the collected controllers and pods of each namespace, used to diff their templates
+// against the running pods.
+type rolloutState struct {
+	controllers map[string][]rolloutController
+	pods        map[string][]rolloutPod
+	mutex       sync.Mutex
+}
+
+func newRolloutState() *rolloutState {
+	return &rolloutState{controllers: map[string][]rolloutController{}, pods: map[string][]rolloutPod{}}
+}
+
+// recordController records the pod template of the given StatefulSet or Deployment.
+func (s *rolloutState) recordController(kind string, obj *unstructured.Unstructured) error {
+	controller := rolloutController{kind: kind, name: obj.GetName()}
+
+	var (
+		selector *metav1.LabelSelector
+		template corev1.PodTemplateSpec
+	)
+
+	switch kind {
+	case internal.STSKind:
+		sts := &appsv1.StatefulSet{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, sts); err != nil {
+			return err
+		}
+
+		selector, template = sts.Spec.Selector, sts.Spec.Template
+		controller.updateRevision = sts.Status.UpdateRevision
+		controller.replicas = replicasOrDefault(sts.Spec.Replicas)
+	case internal.DeployKind:
+		deploy := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, deploy); err != nil {
+			return err
+		}
+
+		selector, template = deploy.Spec.Selector, deploy.Spec.Template
+		controller.replicas = replicasOrDefault(deploy.Spec.Replicas)
+	default:
+		return nil
+	}
+
+	podSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return err
+	}
+
+	templateSpec, err := json.Marshal(template.Spec)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(templateSpec)
+	controller.selector = podSelector
+	controller.templateHash = hex.EncodeToString(sum[:])[:16]
+	controller.images = containerImages(template.Spec.Containers)
+
+	s.mutex.Lock()
+	s.controllers[obj.GetNamespace()] = append(s.controllers[obj.GetNamespace()], controller)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// recordPod records the images, revision and readiness of the given pod.
+func (s *rolloutState) recordPod(pod *corev1.Pod) {
+	rollPod := rolloutPod{
+		labels: pod.Labels,
+		images: containerImages(pod.Spec.Containers),
+		name:   pod.Name,
+		ready:  isPodReady(pod),
+	}
+
+	rollPod.revision = pod.Labels[appsv1.ControllerRevisionHashLabelKey]
+	if rollPod.revision == "" {
+		rollPod.revision = pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+	}
+
+	s.mutex.Lock()
+	s.pods[pod.Namespace] = append(s.pods[pod.Namespace], rollPod)
+	s.mutex.Unlock()
+}
+
+// writeDiffs writes RolloutDiffFile in the directory of each controller of the given namespace, whose pods differ
+// from its template.
+func (s *rolloutState) writeDiffs(ns, rootOutputPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for idx := range s.controllers[ns] {
+		controller := &s.controllers[ns][idx]
+
+		data, ok := controller.diff(s.pods[ns])
+		if !ok {
+			continue
+		}
+
+		diffDir := filepath.Join(rootOutputPath, KindDirNames[controller.kind], controller.name)
+		if err := os.MkdirAll(diffDir, os.ModePerm); err != nil {
+			return err
+		}
+
+		if err := populateScraperDir(data, filepath.Join(diffDir, RolloutDiffFile)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diff formats the template of the controller along with the images, revision and readiness of its pods.
+// It returns false if all the pods match the template.
+func (c *rolloutController) diff(pods []rolloutPod) ([]byte, bool) {
+	var (
+		buf     bytes.Buffer
+		podsBuf bytes.Buffer
+		differs bool
+	)
+
+	fmt.Fprintf(&buf, "%s: %s\n", c.kind, c.name)
+	fmt.Fprintf(&buf, "  Replicas: %d\n", c.replicas)
+	fmt.Fprintf(&buf, "  Template hash: %s\n", c.templateHash)
+
+	if c.kind == internal.STSKind {
+		fmt.Fprintf(&buf, "  Update revision: %s\n", valueOrNone(c.updateRevision))
+	}
+
+	fmt.Fprintf(&buf, "  Template images: %s\n\n", valueOrNone(formatLabels(c.images)))
+
+	for idx := range pods {
+		pod := &pods[idx]
+		if c.selector.Empty() || !c.selector.Matches(labels.Set(pod.labels)) {
+			continue
+		}
+
+		differences := c.podDifferences(pod)
+		differs = differs || len(differences) > 0
+
+		fmt.Fprintf(&podsBuf, "Pod: %s\n", pod.name)
+		fmt.Fprintf(&podsBuf, "  Revision: %s\n", valueOrNone(pod.revision))
+		fmt.Fprintf(&podsBuf, "  Ready: %t\n", pod.ready)
+		fmt.Fprintf(&podsBuf, "  Images: %s\n", valueOrNone(formatLabels(pod.images)))
+		fmt.Fprintf(&podsBuf, "  Differences: %s\n\n", valueOrNone(strings.Join(differences, "; ")))
+	}
+
+	if !differs {
+		return nil, false
+	}
+
+	buf.Write(podsBuf.Bytes())
+
+	return buf.Bytes(), true
+}
+
+// podDifferences returns the differences of the given pod from the template.
+func (c *rolloutController) podDifferences(pod *rolloutPod) []string {
+	var differences []string
+
+	if c.updateRevision != "" && pod.revision != "" && pod.revision != c.updateRevision {
+		differences = append(differences, fmt.Sprintf("revision %s, expected %s", pod.revision, c.updateRevision))
+	}
+
+	names := make([]string, 0, len(c.images))
+	for name := range c.images {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		image, ok := pod.images[name]
+
+		switch {
+		case !ok:
+			differences = append(differences, fmt.Sprintf("container %s missing", name))
+		case image != c.images[name]:
+			differences = append(differences, fmt.Sprintf("container %s image %s, expected %s", name, image,
+				c.images[name]))
+		}
+	}
+
+	return differences
+}
+
+func containerImages(containers []corev1.Container) map[string]string {
+	images := make(map[string]string, len(containers))
+
+	for idx := range containers {
+		images[containers[idx].Name] = containers[idx].Image
+	}
+
+	return images
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for idx := range pod.Status.Conditions {
+		if pod.Status.Conditions[idx].Type == corev1.PodReady {
+			return pod.Status.Conditions[idx].Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// replicasOrDefault returns the given replicas, or 1 which is the default if replicas is not set.
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+
+	return *replicas
+}
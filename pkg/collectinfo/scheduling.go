@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const SchedulingFile = "scheduling.txt"
+
+// failedSchedulingReason is the reason of the events recorded by the scheduler for unschedulable pods.
+const failedSchedulingReason = "FailedScheduling"
+
+// podScheduling is the scheduling details of a Pending pod.
+type podScheduling struct {
+	requests     corev1.ResourceList
+	nodeSelector map[string]string
+	affinity     *corev1.Affinity
+	pod          string
+	reason       string
+	message      string
+	lastEvent    string
+}
+
+// podSchedulingDetails returns the scheduling details of the given pod, if it is Pending and failed scheduling.
+// Unschedulable reason is taken from the PodScheduled condition and the latest FailedScheduling event.
+func podSchedulingDetails(pod *corev1.Pod, events []corev1.Event) (podScheduling, bool) {
+	if pod.Status.Phase != corev1.PodPending {
+		return podScheduling{}, false
+	}
+
+	details := podScheduling{
+		requests:     podRequests(pod),
+		nodeSelector: pod.Spec.NodeSelector,
+		affinity:     pod.Spec.Affinity,
+		pod:          pod.Name,
+	}
+
+	for idx := range pod.Status.Conditions {
+		condition := &pod.Status.Conditions[idx]
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			details.reason = condition.Reason
+			details.message = strings.TrimSpace(condition.Message)
+		}
+	}
+
+	var lastEventTime time.Time
+
+	for idx := range events {
+		if events[idx].Reason != failedSchedulingReason {
+			continue
+		}
+
+		if details.lastEvent == "" || eventTime(&events[idx]).After(lastEventTime) {
+			details.lastEvent = strings.TrimSpace(events[idx].Message)
+			lastEventTime = eventTime(&events[idx])
+		}
+	}
+
+	return details, details.reason != "" || details.lastEvent != ""
+}
+
+// podRequests returns the effective resource requests of the pod used by the scheduler, which is the maximum of
+// the sum of container requests and the requests of any init container.
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+
+	for idx := range pod.Spec.Containers {
+		for name, quantity := range pod.Spec.Containers[idx].Resources.Requests {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+
+	for idx := range pod.Spec.InitContainers {
+		for name, quantity := range pod.Spec.InitContainers[idx].Resources.Requests {
+			if total, ok := requests[name]; !ok || quantity.Cmp(total) > 0 {
+				requests[name] = quantity
+			}
+		}
+	}
+
+	return requests
+}
+
+// formatScheduling formats the scheduling details of Pending pods.
+func formatScheduling(pods []podScheduling) []byte {
+	var buf bytes.Buffer
+
+	for idx := range pods {
+		details := &pods[idx]
+
+		fmt.Fprintf(&buf, "Pod: %s\n", details.pod)
+		fmt.Fprintf(&buf, "  Reason: %s\n", valueOrNone(details.reason))
+		fmt.Fprintf(&buf, "  Message: %s\n", valueOrNone(details.message))
+		fmt.Fprintf(&buf, "  Last %s event: %s\n", failedSchedulingReason, valueOrNone(details.lastEvent))
+		fmt.Fprintf(&buf, "  Requests: %s\n", valueOrNone(formatResourceList(details.requests)))
+		fmt.Fprintf(&buf, "  Node selector: %s\n", valueOrNone(formatLabels(details.nodeSelector)))
+
+		if details.affinity == nil {
+			fmt.Fprintf(&buf, "  Affinity: <none>\n")
+		} else if data, err := yaml.Marshal(details.affinity); err == nil {
+			fmt.Fprintf(&buf, "  Affinity:\n")
+
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				fmt.Fprintf(&buf, "    %s\n", line)
+			}
+		}
+
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes()
+}
+
+func formatResourceList(resources corev1.ResourceList) string {
+	items := make([]string, 0, len(resources))
+
+	for name, quantity := range resources {
+		items = append(items, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+
+	sort.Strings(items)
+
+	return strings.Join(items, ", ")
+}
+
+func formatLabels(labels map[string]string) string {
+	items := make([]string, 0, len(labels))
+
+	for key, value := range labels {
+		items = append(items, key+"="+value)
+	}
+
+	sort.Strings(items)
+
+	return strings.Join(items, ", ")
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+
+	return value
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sync"
+)
+
+const SnapshotFile = "cluster_snapshot.json"
+
+// clusterSnapshot is a map of kind to its objects collected across namespaces and cluster scope, written in
+// SnapshotFile instead of a file per object. Kinds are collected concurrently, so it is guarded by a mutex.
+type clusterSnapshot struct {
+	objects map[string][]interface{}
+	mutex   sync.Mutex
+}
+
+func newClusterSnapshot() *clusterSnapshot {
+	return &clusterSnapshot{objects: map[string][]interface{}{}}
+}
+
+// add records the given object of the kind in the snapshot.
+func (s *clusterSnapshot) add(kind string, obj interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.objects[kind] = append(s.objects[kind], obj)
+}
+
+// write writes SnapshotFile in the given directory.
+func (s *clusterSnapshot) write(rootOutputPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.Marshal(s.objects)
+	if err != nil {
+		return err
+	}
+
+	return populateScraperDir(data, filepath.Join(rootOutputPath, SnapshotFile))
+}
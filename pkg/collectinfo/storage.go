@@ -0,0 +1,228 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const (
+	StorageSummaryFile      = "storage_summary.txt"
+	StorageClassSummaryFile = "storageclass_summary.txt"
+)
+
+// defaultStorageClassAnnotations mark the default StorageClass of the cluster
+var defaultStorageClassAnnotations = []string{
+	"storageclass.kubernetes.io/is-default-class",
+	"storageclass.beta.kubernetes.io/is-default-class",
+}
+
+// storageState records the collected PVCs, PVs, StorageClasses and CSIDrivers, used to correlate them in the
+// storage summaries.
+type storageState struct {
+	pvs            map[string]*corev1.PersistentVolume
+	storageClasses map[string]*v1.StorageClass
+	csiDrivers     sets.Set[string]
+	pvcs           []*corev1.PersistentVolumeClaim
+	mutex          sync.Mutex
+}
+
+func newStorageState() *storageState {
+	return &storageState{
+		pvs:            map[string]*corev1.PersistentVolume{},
+		storageClasses: map[string]*v1.StorageClass{},
+		csiDrivers:     sets.Set[string]{},
+	}
+}
+
+// record records the given collected object, if it is a PVC, PV, StorageClass or CSIDriver.
+func (s *storageState) record(kind string, obj *unstructured.Unstructured) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch kind {
+	case internal.PVCKind:
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pvc); err != nil {
+			return err
+		}
+
+		s.pvcs = append(s.pvcs, pvc)
+	case internal.PVKind:
+		pv := &corev1.PersistentVolume{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pv); err != nil {
+			return err
+		}
+
+		s.pvs[pv.Name] = pv
+	case internal.SCKind:
+		storageClass := &v1.StorageClass{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, storageClass); err != nil {
+			return err
+		}
+
+		s.storageClasses[storageClass.Name] = storageClass
+	case internal.CSIDriverKind:
+		s.csiDrivers.Insert(obj.GetName())
+	}
+
+	return nil
+}
+
+// writeSummary writes StorageSummaryFile correlating each collected PVC with its PV and StorageClass, and
+// StorageClassSummaryFile with the collected StorageClasses. Each is not written if no such object is collected.
+func (s *storageState) writeSummary(rootOutputPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.storageClasses) > 0 {
+		if err := populateScraperDir(s.formatStorageClasses(),
+			filepath.Join(rootOutputPath, StorageClassSummaryFile)); err != nil {
+			return err
+		}
+	}
+
+	if len(s.pvcs) == 0 {
+		return nil
+	}
+
+	return populateScraperDir(s.format(), filepath.Join(rootOutputPath, StorageSummaryFile))
+}
+
+// format formats the PVCs along with their PVs and StorageClasses in a table.
+// PVs and StorageClasses which are not collected are shown as <none>.
+func (s *storageState) format() []byte {
+	var buf bytes.Buffer
+
+	sort.Slice(s.pvcs, func(i, j int) bool {
+		if s.pvcs[i].Namespace != s.pvcs[j].Namespace {
+			return s.pvcs[i].Namespace < s.pvcs[j].Namespace
+		}
+
+		return s.pvcs[i].Name < s.pvcs[j].Name
+	})
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tPVC\tPHASE\tREQUESTED\tALLOCATED\tPV\tPV PHASE\tPV CAPACITY\tRECLAIM POLICY\t"+
+		"STORAGECLASS\tPROVISIONER\tBINDING MODE")
+
+	for _, pvc := range s.pvcs {
+		pvPhase, pvCapacity, reclaimPolicy := "<none>", "<none>", "<none>"
+		storageClassName := ""
+
+		if pvc.Spec.StorageClassName != nil {
+			storageClassName = *pvc.Spec.StorageClassName
+		}
+
+		if pv, ok := s.pvs[pvc.Spec.VolumeName]; ok {
+			pvPhase = valueOrNone(string(pv.Status.Phase))
+			pvCapacity = quantityOrNone(pv.Spec.Capacity, corev1.ResourceStorage)
+			reclaimPolicy = string(pv.Spec.PersistentVolumeReclaimPolicy)
+
+			if storageClassName == "" {
+				storageClassName = pv.Spec.StorageClassName
+			}
+		}
+
+		provisioner, bindingMode := "<none>", "<none>"
+
+		if storageClass, ok := s.storageClasses[storageClassName]; ok {
+			provisioner = storageClass.Provisioner
+
+			if storageClass.VolumeBindingMode != nil {
+				bindingMode = string(*storageClass.VolumeBindingMode)
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", pvc.Namespace, pvc.Name,
+			valueOrNone(string(pvc.Status.Phase)), quantityOrNone(pvc.Spec.Resources.Requests, corev1.ResourceStorage),
+			quantityOrNone(pvc.Status.Capacity, corev1.ResourceStorage), valueOrNone(pvc.Spec.VolumeName), pvPhase,
+			pvCapacity, valueOrNone(reclaimPolicy), valueOrNone(storageClassName), provisioner, bindingMode)
+	}
+
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
+
+// formatStorageClasses formats the StorageClasses in a table, marking the default one and whether a CSIDriver
+// is registered for its provisioner.
+func (s *storageState) formatStorageClasses() []byte {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDEFAULT\tPROVISIONER\tCSIDRIVER\tRECLAIM POLICY\tBINDING MODE\tEXPANSION\tPARAMETERS")
+
+	for _, name := range sets.List(sets.KeySet(s.storageClasses)) {
+		storageClass := s.storageClasses[name]
+		reclaimPolicy, bindingMode := "<none>", "<none>"
+
+		if storageClass.ReclaimPolicy != nil {
+			reclaimPolicy = string(*storageClass.ReclaimPolicy)
+		}
+
+		if storageClass.VolumeBindingMode != nil {
+			bindingMode = string(*storageClass.VolumeBindingMode)
+		}
+
+		parameters := make([]string, 0, len(storageClass.Parameters))
+		for _, key := range sets.List(sets.KeySet(storageClass.Parameters)) {
+			parameters = append(parameters, key+"="+storageClass.Parameters[key])
+		}
+
+		fmt.Fprintf(w, "%s\t%t\t%s\t%t\t%s\t%s\t%t\t%s\n", name, isDefaultStorageClass(storageClass),
+			storageClass.Provisioner, s.csiDrivers.Has(storageClass.Provisioner), reclaimPolicy, bindingMode,
+			storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion,
+			valueOrNone(strings.Join(parameters, ",")))
+	}
+
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
+
+func isDefaultStorageClass(storageClass *v1.StorageClass) bool {
+	for _, annotation := range defaultStorageClassAnnotations {
+		if storageClass.Annotations[annotation] == "true" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func quantityOrNone(resources corev1.ResourceList, name corev1.ResourceName) string {
+	quantity, ok := resources[name]
+	if !ok {
+		return "<none>"
+	}
+
+	return quantity.String()
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+)
+
+// RunCollectInfoToFile runs the collection and writes the archive of the whole bundle in the given file, or streams
+// it to stdout if the file is configuration.StdoutOutputFile. The logger of params must not write to stdout in that
+// case, see configuration.LogWriter.
+func RunCollectInfoToFile(ctx context.Context, params *configuration.Parameters, fileName string) error {
+	if collect, err := shouldCollect(ctx, params); err != nil || !collect {
+		return err
+	}
+
+	if fileName == configuration.StdoutOutputFile {
+		return CollectInfoToWriter(ctx, params, os.Stdout)
+	}
+
+	archiveMode := archiveFileMode(params)
+
+	file, err := os.OpenFile(filepath.Clean(fileName), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, archiveMode)
+	if err != nil {
+		return err
+	}
+
+	if err := CollectInfoToWriter(ctx, params, file); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	// set mode explicitly, as mode given while creating file is masked by umask
+	return os.Chmod(fileName, archiveMode)
+}
+
+// CollectInfoToWriter runs the collection in a temporary directory and writes the compressed tar of the whole bundle
+// in w instead of tar files, for example to pipe it into another command without temporary files in the output
+// directory. The collection log is still added to the bundle.
+func CollectInfoToWriter(ctx context.Context, params *configuration.Parameters, w io.Writer) error {
+	tmpDir, err := os.MkdirTemp("", "akoctl-collectinfo-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	rootOutputPath := filepath.Join(tmpDir, BundleDirName(params))
+	if err := os.Mkdir(rootOutputPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	detachLog, err := attachCollectionLog(params, rootOutputPath)
+	if err != nil {
+		return err
+	}
+
+	// log is detached before archiving, this restores the logger if the collection fails before that
+	defer detachLog()
+
+	_, err = collectInfo(ctx, params, tmpDir, w, detachLog)
+
+	return err
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// ErrMissingKinds is returned in strict mode if no objects of some of StrictKinds are found in any of the
+// given namespaces. The bundle is still archived.
+var ErrMissingKinds = errors.New("expected Aerospike kinds not found")
+
+// StrictKinds are the core Aerospike kinds expected in at least one of the given namespaces in strict mode
+var StrictKinds = []string{internal.AerospikeClusterKind}
+
+// kindCounter counts the objects of each kind listed in the given namespaces, irrespective of the filters
+// applied on the collected objects.
+type kindCounter struct {
+	counts map[string]int
+	mutex  sync.Mutex
+}
+
+func newKindCounter() *kindCounter {
+	return &kindCounter{counts: map[string]int{}}
+}
+
+func (c *kindCounter) add(kind string, count int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.counts[kind] += count
+}
+
+// missing returns the given kinds of which no objects are found.
+func (c *kindCounter) missing(kinds []string) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	missing := sets.New[string]()
+
+	for _, kind := range kinds {
+		if c.counts[kind] == 0 {
+			missing.Insert(kind)
+		}
+	}
+
+	return sets.List(missing)
+}
+
+// checkStrict records StrictKinds not found in any of the given namespaces in the report, and returns
+// ErrMissingKinds if there are any. Namespaces are not checked if only the cluster scope is collected.
+func checkStrict(params *configuration.Parameters, state *collectionState, report *CollectionReport) error {
+	if !params.NamespaceScoped() {
+		return nil
+	}
+
+	report.MissingKinds = state.foundKinds.missing(StrictKinds)
+	if len(report.MissingKinds) == 0 {
+		return nil
+	}
+
+	params.Logger.Error("Expected Aerospike kinds not found in any of the namespaces",
+		zap.Strings("kinds", report.MissingKinds), zap.Strings("namespaces", sets.List(params.Namespaces)))
+
+	return fmt.Errorf("%w: %s in namespaces %s", ErrMissingKinds, strings.Join(report.MissingKinds, ", "),
+		strings.Join(sets.List(params.Namespaces), ", "))
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// StuckDeletionsFile lists the collected objects and namespaces being deleted, with the finalizers blocking them.
+const StuckDeletionsFile = "stuck_deletions.txt"
+
+// stuckDeletion is an object with deletionTimestamp set, which is not removed till its finalizers are cleared.
+type stuckDeletion struct {
+	deletionTime time.Time
+	kind         string
+	namespace    string
+	name         string
+	uid          string
+	finalizers   []string
+	// conditions are the reasons reported by the namespace controller for a terminating namespace
+	conditions []string
+}
+
+type deletionRecorder struct {
+	deletions []stuckDeletion
+	mutex     sync.Mutex
+}
+
+func newDeletionRecorder() *deletionRecorder {
+	return &deletionRecorder{}
+}
+
+// record records the given collected object if it is being deleted.
+func (r *deletionRecorder) record(kind string, obj metav1.Object) {
+	if obj.GetDeletionTimestamp() == nil {
+		return
+	}
+
+	r.add(stuckDeletion{
+		deletionTime: obj.GetDeletionTimestamp().Time,
+		kind:         kind,
+		namespace:    obj.GetNamespace(),
+		name:         obj.GetName(),
+		uid:          string(obj.GetUID()),
+		finalizers:   obj.GetFinalizers(),
+	})
+}
+
+func (r *deletionRecorder) add(deletion stuckDeletion) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.deletions = append(r.deletions, deletion)
+}
+
+// recordNamespace records the given namespace if it is terminating, along with the finalizers in its spec and the
+// conditions explaining the remaining content. The namespace is skipped if it can not be read.
+func recordNamespace(ctx context.Context, params *configuration.Parameters, state *collectionState, ns string) {
+	namespace, err := params.ClientSet.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+	if err != nil {
+		params.Logger.Warn("Not able to get namespace, skipping it in stuck deletions", zap.String("namespace", ns),
+			zap.Error(err))
+
+		return
+	}
+
+	if namespace.DeletionTimestamp == nil {
+		return
+	}
+
+	deletion := stuckDeletion{
+		deletionTime: namespace.DeletionTimestamp.Time,
+		kind:         internal.NamespaceKind,
+		name:         namespace.Name,
+		uid:          string(namespace.UID),
+		finalizers:   append([]string{}, namespace.Finalizers...),
+	}
+
+	for _, finalizer := range namespace.Spec.Finalizers {
+		deletion.finalizers = append(deletion.finalizers, string(finalizer))
+	}
+
+	for idx := range namespace.Status.Conditions {
+		condition := &namespace.Status.Conditions[idx]
+		if condition.Status == corev1.ConditionTrue {
+			deletion.conditions = append(deletion.conditions,
+				fmt.Sprintf("%s: %s", condition.Type, strings.TrimSpace(condition.Message)))
+		}
+	}
+
+	state.stuckDeletions.add(deletion)
+}
+
+// write writes StuckDeletionsFile with the recorded objects being deleted, oldest first, along with their collected
+// dependents from the ownership graph. It is not written if no object is being deleted.
+func (r *deletionRecorder) write(owners *ownershipGraph, rootOutputPath string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.deletions) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(r.deletions, func(i, j int) bool {
+		return r.deletions[i].deletionTime.Before(r.deletions[j].deletionTime)
+	})
+
+	graph := owners.graph()
+
+	nodes := make(map[string]*OwnershipNode, len(graph.Nodes))
+	for idx := range graph.Nodes {
+		nodes[graph.Nodes[idx].UID] = &graph.Nodes[idx]
+	}
+
+	dependents := map[string][]string{}
+
+	for _, edge := range graph.Edges {
+		if node, ok := nodes[edge.Dependent]; ok {
+			dependents[edge.Owner] = append(dependents[edge.Owner], node.Kind+"/"+node.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	for idx := range r.deletions {
+		deletion := &r.deletions[idx]
+
+		fmt.Fprintf(&buf, "%s: %s\n", deletion.kind, filepath.Join(deletion.namespace, deletion.name))
+		fmt.Fprintf(&buf, "  Deletion timestamp: %s\n", deletion.deletionTime.UTC().Format(time.RFC3339))
+		fmt.Fprintf(&buf, "  Finalizers: %s\n", valueOrNone(strings.Join(deletion.finalizers, ", ")))
+
+		if len(deletion.conditions) > 0 {
+			fmt.Fprintf(&buf, "  Conditions:\n")
+
+			for _, condition := range deletion.conditions {
+				fmt.Fprintf(&buf, "    %s\n", condition)
+			}
+		}
+
+		if len(dependents[deletion.uid]) > 0 {
+			sort.Strings(dependents[deletion.uid])
+			fmt.Fprintf(&buf, "  Dependents: %s\n", strings.Join(dependents[deletion.uid], ", "))
+		}
+
+		buf.WriteString("\n")
+	}
+
+	return populateScraperDir(buf.Bytes(), filepath.Join(rootOutputPath, StuckDeletionsFile))
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// warningEventSelector selects the events shown in the summary
+const warningEventSelector = "type=" + corev1.EventTypeWarning
+
+// RunSummary writes the triage summary of the namespaces of params in the given file, or to stdout if the file is
+// configuration.StdoutOutputFile. This is the output of configuration.FormatSummary, no bundle is collected.
+func RunSummary(ctx context.Context, params *configuration.Parameters, fileName string) error {
+	if fileName == configuration.StdoutOutputFile {
+		return WriteSummary(ctx, params, os.Stdout)
+	}
+
+	file, err := os.OpenFile(filepath.Clean(fileName), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, archiveFileMode(params))
+	if err != nil {
+		return err
+	}
+
+	if err := WriteSummary(ctx, params, file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// WriteSummary writes the AerospikeClusters with their health, the pods with their phases and the warning events of
+// each namespace of params in w, limited to the AerospikeCluster given in params if any. Objects are read directly,
+// without collecting them or their logs, for a first look at a cluster.
+func WriteSummary(ctx context.Context, params *configuration.Parameters, w io.Writer) error {
+	for _, ns := range sets.List(params.Namespaces) {
+		var buf bytes.Buffer
+
+		fmt.Fprintf(&buf, "Namespace: %s\n", ns)
+
+		if err := summarizeClusters(ctx, params, ns, &buf); err != nil {
+			return err
+		}
+
+		pods, err := summarizePods(ctx, params, ns, &buf)
+		if err != nil {
+			return err
+		}
+
+		if err := summarizeWarningEvents(ctx, params, ns, pods, &buf); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(&buf)
+
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// summarizeClusters writes the phase, size and health of the AerospikeClusters of the given namespace, with the
+// same checks as FindUnhealthyClusters.
+func summarizeClusters(ctx context.Context, params *configuration.Parameters, ns string, buf *bytes.Buffer) error {
+	clusters := &unstructured.UnstructuredList{}
+	clusters.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   OperatorGroup,
+		Version: "v1",
+		Kind:    internal.AerospikeClusterKind,
+	})
+
+	fmt.Fprintln(buf, "\nAerospikeClusters:")
+
+	if err := params.K8sClient.List(ctx, clusters, client.InNamespace(ns)); err != nil {
+		if meta.IsNoMatchError(err) {
+			fmt.Fprintln(buf, "  AerospikeCluster kind not served by the cluster")
+			return nil
+		}
+
+		return err
+	}
+
+	w := tabwriter.NewWriter(buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tPHASE\tSIZE\tHEALTH")
+
+	for idx := range clusters.Items {
+		cluster := &clusters.Items[idx]
+		if params.ClusterName != "" && cluster.GetName() != params.ClusterName {
+			continue
+		}
+
+		reasons, err := clusterHealth(ctx, params, cluster)
+		if err != nil {
+			return err
+		}
+
+		health := "healthy"
+		if len(reasons) > 0 {
+			health = strings.Join(reasons, "; ")
+		}
+
+		phase, _, _ := unstructured.NestedString(cluster.Object, "status", "phase")
+		size := "<none>"
+
+		if value, ok, _ := unstructured.NestedFieldNoCopy(cluster.Object, "spec", "size"); ok {
+			size = fmt.Sprint(value)
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", cluster.GetName(), valueOrNone(phase), size, health)
+	}
+
+	return w.Flush()
+}
+
+// summarizePods writes the phase counts and the status of each pod of the given namespace, and returns the names
+// of the pods.
+func summarizePods(ctx context.Context, params *configuration.Parameters, ns string, buf *bytes.Buffer) (
+	sets.Set[string], error) {
+	opts := metav1.ListOptions{FieldSelector: params.FieldSelectors[internal.PodKind]}
+	if params.ClusterName != "" {
+		opts.LabelSelector = ClusterNameLabel + "=" + params.ClusterName
+	}
+
+	pods, err := params.ClientSet.CoreV1().Pods(ns).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names := sets.New[string]()
+	phases := map[string]int{}
+
+	for idx := range pods.Items {
+		names.Insert(pods.Items[idx].Name)
+		phases[valueOrNone(string(pods.Items[idx].Status.Phase))]++
+	}
+
+	counts := make([]string, 0, len(phases))
+	for phase, count := range phases {
+		counts = append(counts, fmt.Sprintf("%s: %d", phase, count))
+	}
+
+	sort.Strings(counts)
+
+	fmt.Fprintf(buf, "\nPods (%s):\n", strings.Join(counts, ", "))
+
+	w := tabwriter.NewWriter(buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tPHASE\tREADY\tRESTARTS\tNODE")
+
+	for idx := range pods.Items {
+		pod := &pods.Items[idx]
+
+		var restarts int32
+		for statusIdx := range pod.Status.ContainerStatuses {
+			restarts += pod.Status.ContainerStatuses[statusIdx].RestartCount
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\t%t\t%d\t%s\n", pod.Name, valueOrNone(string(pod.Status.Phase)), isPodReady(pod),
+			restarts, valueOrNone(pod.Spec.NodeName))
+	}
+
+	return names, w.Flush()
+}
+
+// summarizeWarningEvents writes the warning events of the given namespace. If the summary is limited to an
+// AerospikeCluster, only the events of the cluster and the given pods are written.
+func summarizeWarningEvents(ctx context.Context, params *configuration.Parameters, ns string, pods sets.Set[string],
+	buf *bytes.Buffer) error {
+	selector := warningEventSelector
+	if eventSelector := params.FieldSelectors[internal.EventKind]; eventSelector != "" {
+		selector += "," + eventSelector
+	}
+
+	eventList, err := params.ClientSet.CoreV1().Events(ns).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return err
+	}
+
+	var events []corev1.Event
+
+	for idx := range eventList.Items {
+		involved := eventList.Items[idx].InvolvedObject
+		if params.ClusterName != "" && !(involved.Kind == internal.PodKind && pods.Has(involved.Name)) &&
+			!(involved.Kind == internal.AerospikeClusterKind && involved.Name == params.ClusterName) {
+			continue
+		}
+
+		events = append(events, eventList.Items[idx])
+	}
+
+	fmt.Fprintln(buf, "\nWarning events:")
+
+	if len(events) == 0 {
+		fmt.Fprintln(buf, "  No warning events")
+		return nil
+	}
+
+	_, err = buf.Write(formatEvents(events))
+
+	return err
+}
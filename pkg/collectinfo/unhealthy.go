@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// completedPhase is the phase of an AerospikeCluster once the operator has reconciled it
+const completedPhase = "Completed"
+
+// readyConditionTypes are the status conditions which must be true for a healthy AerospikeCluster
+var readyConditionTypes = sets.New("Ready", "Available")
+
+// FindUnhealthyClusters returns the reasons of the AerospikeClusters of the given namespaces which are unhealthy,
+// limited to the AerospikeCluster given in params if any. A cluster is unhealthy if its status is not reported,
+// its phase is not Completed, its ready conditions are not true, its reconciled size or image differs from its
+// spec, or any of its pods is not ready. No cluster is unhealthy if the operator CRDs are not installed.
+func FindUnhealthyClusters(ctx context.Context, params *configuration.Parameters) ([]string, error) {
+	var unhealthy []string
+
+	for _, ns := range sets.List(params.Namespaces) {
+		clusters := &unstructured.UnstructuredList{}
+		clusters.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   OperatorGroup,
+			Version: "v1",
+			Kind:    internal.AerospikeClusterKind,
+		})
+
+		if err := params.K8sClient.List(ctx, clusters, client.InNamespace(ns)); err != nil {
+			if meta.IsNoMatchError(err) {
+				params.Logger.Info("AerospikeCluster kind not served by the cluster, no cluster is unhealthy")
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		for idx := range clusters.Items {
+			cluster := &clusters.Items[idx]
+			if params.ClusterName != "" && cluster.GetName() != params.ClusterName {
+				continue
+			}
+
+			reasons, err := clusterHealth(ctx, params, cluster)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, reason := range reasons {
+				unhealthy = append(unhealthy, fmt.Sprintf("%s: %s", filepath.Join(ns, cluster.GetName()), reason))
+			}
+		}
+	}
+
+	return unhealthy, nil
+}
+
+// clusterHealth returns the reasons for which the given AerospikeCluster is unhealthy.
+func clusterHealth(ctx context.Context, params *configuration.Parameters, cluster *unstructured.Unstructured) (
+	[]string, error) {
+	status, ok, _ := unstructured.NestedMap(cluster.Object, "status")
+	if !ok || len(status) == 0 {
+		return []string{"status not reported, the cluster is not reconciled yet"}, nil
+	}
+
+	var reasons []string
+
+	if phase, ok, _ := unstructured.NestedString(status, "phase"); ok && phase != completedPhase {
+		reasons = append(reasons, fmt.Sprintf("phase is %s", phase))
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+	for _, condition := range conditions {
+		fields, ok := condition.(map[string]interface{})
+		if !ok || !readyConditionTypes.Has(fmt.Sprint(fields["type"])) {
+			continue
+		}
+
+		if fields["status"] != string(metav1.ConditionTrue) {
+			reasons = append(reasons, fmt.Sprintf("condition %s is %v: %v", fields["type"], fields["status"],
+				fields["message"]))
+		}
+	}
+
+	for _, field := range []string{"size", "image"} {
+		specValue, specOK, _ := unstructured.NestedFieldNoCopy(cluster.Object, "spec", field)
+		statusValue, statusOK, _ := unstructured.NestedFieldNoCopy(status, field)
+
+		if specOK && (!statusOK || fmt.Sprint(specValue) != fmt.Sprint(statusValue)) {
+			reasons = append(reasons, fmt.Sprintf("reconciled %s %v differs from spec %s %v", field, statusValue,
+				field, specValue))
+		}
+	}
+
+	pods, err := params.ClientSet.CoreV1().Pods(cluster.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: ClusterNameLabel + "=" + cluster.GetName(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range pods.Items {
+		if !isPodReady(&pods.Items[idx]) {
+			reasons = append(reasons, fmt.Sprintf("pod %s is not ready, phase %s", pods.Items[idx].Name,
+				pods.Items[idx].Status.Phase))
+		}
+	}
+
+	return reasons, nil
+}
+
+// shouldCollect returns false if the collection is to be skipped as per OnlyIfUnhealthy of params, which is when
+// all the AerospikeClusters are healthy.
+func shouldCollect(ctx context.Context, params *configuration.Parameters) (bool, error) {
+	if !params.OnlyIfUnhealthy {
+		return true, nil
+	}
+
+	unhealthy, err := FindUnhealthyClusters(ctx, params)
+	if err != nil {
+		return false, fmt.Errorf("not able to check health of AerospikeClusters: %v", err)
+	}
+
+	if len(unhealthy) == 0 {
+		params.Logger.Info("All AerospikeClusters are healthy, skipping collection")
+		return false, nil
+	}
+
+	params.Logger.Info("Found unhealthy AerospikeClusters, collecting", zap.Strings("reasons", unhealthy))
+
+	return true, nil
+}
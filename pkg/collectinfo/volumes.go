@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const VolumesFile = "volumes.txt"
+
+// formatVolumes formats the volumes of the given Aerospike pod with their source and the paths where these are
+// mounted or attached as block devices in each container, so that PVCs can be correlated to the paths used by the
+// Aerospike server. It returns nil if the pod has no volumes.
+func formatVolumes(pod *corev1.Pod) []byte {
+	if _, ok := pod.Labels[ClusterNameLabel]; !ok || len(pod.Spec.Volumes) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "VOLUME\tSOURCE\tCONTAINER\tTYPE\tPATH\tSUB PATH\tREAD ONLY")
+
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+
+	for idx := range pod.Spec.Volumes {
+		volume := &pod.Spec.Volumes[idx]
+		source := volumeSource(volume)
+		used := false
+
+		for containerIdx := range containers {
+			container := &containers[containerIdx]
+
+			for _, mount := range container.VolumeMounts {
+				if mount.Name == volume.Name {
+					fmt.Fprintf(w, "%s\t%s\t%s\tmount\t%s\t%s\t%s\n", volume.Name, source, container.Name,
+						mount.MountPath, valueOrNone(mount.SubPath), strconv.FormatBool(mount.ReadOnly))
+
+					used = true
+				}
+			}
+
+			for _, device := range container.VolumeDevices {
+				if device.Name == volume.Name {
+					fmt.Fprintf(w, "%s\t%s\t%s\tdevice\t%s\t%s\t%s\n", volume.Name, source, container.Name,
+						device.DevicePath, valueOrNone(""), strconv.FormatBool(false))
+
+					used = true
+				}
+			}
+		}
+
+		if !used {
+			none := valueOrNone("")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", volume.Name, source, none, none, none, none, none)
+		}
+	}
+
+	_ = w.Flush()
+
+	return buf.Bytes()
+}
+
+// volumeSource describes the source of the given volume, along with the name of the object it refers to.
+func volumeSource(volume *corev1.Volume) string {
+	switch {
+	case volume.PersistentVolumeClaim != nil:
+		return "pvc/" + volume.PersistentVolumeClaim.ClaimName
+	case volume.ConfigMap != nil:
+		return "configmap/" + volume.ConfigMap.Name
+	case volume.Secret != nil:
+		return "secret/" + volume.Secret.SecretName
+	case volume.EmptyDir != nil:
+		return "emptydir"
+	case volume.HostPath != nil:
+		return "hostpath/" + volume.HostPath.Path
+	case volume.Projected != nil:
+		return "projected"
+	case volume.DownwardAPI != nil:
+		return "downwardapi"
+	case volume.Ephemeral != nil:
+		return "ephemeral"
+	case volume.CSI != nil:
+		return "csi/" + volume.CSI.Driver
+	default:
+		return "other"
+	}
+}
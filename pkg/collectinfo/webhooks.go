@@ -0,0 +1,177 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectinfo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+// WebhookHealthFile correlates the operator webhooks with their backing services.
+const WebhookHealthFile = "webhook_health.txt"
+
+// webhookHealth is the health of a webhook of a webhook configuration.
+type webhookHealth struct {
+	configuration string
+	webhook       string
+	service       string
+	endpoints     string
+	caBundle      string
+}
+
+// isOperatorWebhookConfig returns true if the webhook configuration of the given kind belongs to the operator.
+func isOperatorWebhookConfig(kind, name string) bool {
+	switch kind {
+	case internal.MutatingWebhookKind:
+		return strings.HasPrefix(name, MutatingWebhookPrefix) || name == MutatingWebhookName
+	case internal.ValidatingWebhookKind:
+		return strings.HasPrefix(name, ValidatingWebhookPrefix) || name == ValidatingWebhookName
+	default:
+		return false
+	}
+}
+
+// captureWebhookHealth writes the service referenced by each operator webhook, readiness of its endpoints
+// and fingerprint of its caBundle in WebhookHealthFile.
+func captureWebhookHealth(ctx context.Context, params *configuration.Parameters, rootOutputPath string) error {
+	var healths []webhookHealth
+
+	mutatingConfigs, err := params.ClientSet.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx,
+		metav1.ListOptions{})
+	if err != nil {
+		params.Logger.Warn("Not able to list webhook configurations, skipping webhook health",
+			zap.String("kind", internal.MutatingWebhookKind), zap.Error(err))
+	} else {
+		for idx := range mutatingConfigs.Items {
+			config := &mutatingConfigs.Items[idx]
+			if !isOperatorWebhookConfig(internal.MutatingWebhookKind, config.Name) {
+				continue
+			}
+
+			for webhookIdx := range config.Webhooks {
+				webhook := &config.Webhooks[webhookIdx]
+				healths = append(healths,
+					checkWebhook(ctx, params, config.Name, webhook.Name, &webhook.ClientConfig))
+			}
+		}
+	}
+
+	validatingConfigs, err := params.ClientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx,
+		metav1.ListOptions{})
+	if err != nil {
+		params.Logger.Warn("Not able to list webhook configurations, skipping webhook health",
+			zap.String("kind", internal.ValidatingWebhookKind), zap.Error(err))
+	} else {
+		for idx := range validatingConfigs.Items {
+			config := &validatingConfigs.Items[idx]
+			if !isOperatorWebhookConfig(internal.ValidatingWebhookKind, config.Name) {
+				continue
+			}
+
+			for webhookIdx := range config.Webhooks {
+				webhook := &config.Webhooks[webhookIdx]
+				healths = append(healths,
+					checkWebhook(ctx, params, config.Name, webhook.Name, &webhook.ClientConfig))
+			}
+		}
+	}
+
+	if len(healths) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "CONFIGURATION\tWEBHOOK\tSERVICE\tENDPOINTS\tCA BUNDLE SHA256")
+
+	for idx := range healths {
+		health := &healths[idx]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", health.configuration, health.webhook, health.service,
+			health.endpoints, health.caBundle)
+	}
+
+	_ = w.Flush()
+
+	return populateScraperDir(buf.Bytes(), filepath.Join(rootOutputPath, WebhookHealthFile))
+}
+
+// checkWebhook returns the health of the given webhook using its client config.
+func checkWebhook(ctx context.Context, params *configuration.Parameters, configName, webhookName string,
+	clientConfig *admissionv1.WebhookClientConfig) webhookHealth {
+	health := webhookHealth{
+		configuration: configName,
+		webhook:       webhookName,
+		caBundle:      "<none>",
+		endpoints:     "-",
+	}
+
+	if len(clientConfig.CABundle) > 0 {
+		sum := sha256.Sum256(clientConfig.CABundle)
+		health.caBundle = hex.EncodeToString(sum[:])
+	}
+
+	if clientConfig.Service == nil {
+		if clientConfig.URL != nil {
+			health.service = "url:" + *clientConfig.URL
+		}
+
+		return health
+	}
+
+	svc := clientConfig.Service
+	health.service = svc.Namespace + "/" + svc.Name
+
+	if svc.Port != nil {
+		health.service += fmt.Sprintf(":%d", *svc.Port)
+	}
+
+	endpoints, err := params.ClientSet.CoreV1().Endpoints(svc.Namespace).Get(ctx, svc.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			health.endpoints = "not found"
+		} else {
+			health.endpoints = "unknown: " + err.Error()
+		}
+
+		return health
+	}
+
+	ready, notReady := 0, 0
+
+	for idx := range endpoints.Subsets {
+		ready += len(endpoints.Subsets[idx].Addresses)
+		notReady += len(endpoints.Subsets[idx].NotReadyAddresses)
+	}
+
+	health.endpoints = fmt.Sprintf("%d ready, %d not ready", ready, notReady)
+
+	return health
+}
@@ -18,143 +18,948 @@ package configuration
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	runtimeConfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const (
+	ScopeNamespace = "namespace"
+	ScopeCluster   = "cluster"
+	ScopeBoth      = "both"
+)
+
+// Formats of the console logs
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
+)
+
+// Compression algorithms of the generated archive
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// Formats of the collection output
+const (
+	// FormatFull writes the bundle with the objects and logs
+	FormatFull = "full"
+	// FormatSummary writes only the triage summary of the namespaces, without the objects and logs
+	FormatSummary = "summary"
+)
+
+// DefaultPerFileGzipThreshold is the size in bytes above which log files are gzipped individually by default
+const DefaultPerFileGzipThreshold = 1 << 20
+
+// DefaultPageSize is the number of objects listed per request by default, like the chunk size of kubectl
+const DefaultPageSize = 500
+
+// StdoutOutputFile is the output file name which streams the generated archive to stdout
+const StdoutOutputFile = "-"
+
+// DefaultAsinfoPort is the service port of the Aerospike server queried by asinfo by default
+const DefaultAsinfoPort = 3000
+
+// DefaultAsinfoCommands are the info commands run in each Aerospike server container by default
+var DefaultAsinfoCommands = []string{"build", "node", "statistics", "namespaces", "sets", "health-outliers"}
+
+// DefaultLogRedactions mask the credentials commonly printed in container logs, like the password of a connection
+// string, the value of password, secret, token and API key pairs and the credentials of authorization headers
+var DefaultLogRedactions = []string{
+	`[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]+:([^/\s@]+)@`,
+	`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key)["']?\s*[:=]\s*["']?([^\s"',;&]+)`,
+	`(?i)authorization:\s*(?:bearer|basic)\s+([A-Za-z0-9._~+/=-]+)`,
+}
+
+// DefaultPrometheusRange is the time range until now of the metrics queried from Prometheus by default
+const DefaultPrometheusRange = time.Hour
+
+// MinInterval is the minimum interval between repeated collections, so that their archives have distinct timestamps
+const MinInterval = time.Second
+
+// Placeholders of the output name template, replaced by the namespace, name, UID, first 8 characters of UID and
+// kind of each collected object
+const (
+	PlaceholderNamespace = "{namespace}"
+	PlaceholderName      = "{name}"
+	PlaceholderUID       = "{uid}"
+	PlaceholderUID8      = "{uid8}"
+	PlaceholderKind      = "{kind}"
 )
 
+// outputNamePlaceholders are the placeholders supported in the output name template
+var outputNamePlaceholders = sets.New(
+	PlaceholderNamespace, PlaceholderName, PlaceholderUID, PlaceholderUID8, PlaceholderKind)
+
+// placeholderRegex matches the placeholders in the output name template
+var placeholderRegex = regexp.MustCompile(`\{[^{}]*\}`)
+
+// supportedFieldSelectors are the fields supported by apiserver in field selectors of pods and events
+var supportedFieldSelectors = map[string]sets.Set[string]{
+	internal.PodKind: sets.New(
+		"metadata.name", "metadata.namespace", "spec.nodeName", "spec.restartPolicy", "spec.schedulerName",
+		"spec.serviceAccountName", "spec.hostNetwork", "status.phase", "status.podIP", "status.podIPs",
+		"status.nominatedNodeName"),
+	internal.EventKind: sets.New(
+		"metadata.name", "metadata.namespace", "involvedObject.kind", "involvedObject.namespace",
+		"involvedObject.name", "involvedObject.uid", "involvedObject.apiVersion", "involvedObject.resourceVersion",
+		"involvedObject.fieldPath", "reason", "reportingComponent", "source", "type"),
+}
+
+// ExtraKind is a kind collected in addition to the default kinds, given using the extra kinds file.
+// Scope is either namespace or cluster, and if NamePrefix is given only the objects whose name starts with it
+// are collected.
+type ExtraKind struct {
+	Group      string `json:"group"`
+	Version    string `json:"version"`
+	Kind       string `json:"kind"`
+	Scope      string `json:"scope"`
+	NamePrefix string `json:"namePrefix,omitempty"`
+}
+
+// RedactionRule masks the fields at the given paths of the objects of a kind, given using the redaction rules file.
+// Each path is a dot separated list of field names, where a list can be indexed as name[0] or name[*] for all
+// of its items, and * matches all fields of a map, for example spec.containers[*].env[*].value.
+type RedactionRule struct {
+	Kind  string   `json:"kind"`
+	Paths []string `json:"paths"`
+}
+
 type Parameters struct {
-	K8sClient     client.Client
-	ClientSet     *kubernetes.Clientset
-	Logger        *zap.Logger
-	Namespaces    sets.Set[string]
-	ClusterScope  bool
-	AllNamespaces bool
+	K8sClient client.Client
+	ClientSet kubernetes.Interface
+	// RunTime is the start time of the collection used in the archive names, the process start time if zero
+	RunTime time.Time
+	// Interval re-runs the collection on the given interval till interrupted, it is run once if zero
+	Interval time.Duration
+	// ExtraKinds are collected in addition to the default kinds
+	ExtraKinds []ExtraKind
+	// RedactionRules mask the fields of the collected objects along with the default rules
+	RedactionRules []RedactionRule
+	// NewExecutor returns the executor of the given exec subresource URL, used to run commands in containers
+	NewExecutor          func(method string, url *url.URL) (remotecommand.Executor, error)
+	Logger               *zap.Logger
+	Namespaces           sets.Set[string]
+	PostCommand          []string
+	LogRedactions        []*regexp.Regexp
+	AsinfoCommands       []string
+	ExcludeNamespaces    sets.Set[string]
+	FieldSelectors       map[string]string
+	LogGrep              *regexp.Regexp
+	NamespaceRegex       *regexp.Regexp
+	FileMode             os.FileMode
+	FollowDuration       time.Duration
+	PrometheusRange      time.Duration
+	ContextTimeout       time.Duration
+	ChangedSince         time.Duration
+	MaxNamespaceLogBytes int64
+	PerFileGzipThreshold int64
+	// Count is the number of latest archives kept with Interval, all the archives are kept if zero
+	Count int
+	// PageSize is the number of objects listed per request, all the objects are listed at once if it is zero
+	PageSize            int64
+	LogMaxSize          int
+	AsinfoPort          int
+	NamePrefix          string
+	BundleName          string
+	ContextName         string
+	Scope               string
+	Compression         string
+	Format              string
+	ResourceVersion     string
+	ClusterName         string
+	AppendTo            string
+	Baseline            string
+	PrometheusURL       string
+	NodeSelector        string
+	LogSelector         string
+	OperatorSelector    string
+	AsinfoUser          string
+	AsinfoSecret        string
+	TargetNode          string
+	OutputNameTemplate  string
+	ClusterScope        bool
+	AllNamespaces       bool
+	Consolidate         bool
+	SingleJSON          bool
+	ParallelCompress    bool
+	PerFileGzip         bool
+	PerNamespaceArchive bool
+	LogTimestamps       bool
+	SinceRestart        bool
+	ExecConfig          bool
+	Asinfo              bool
+	GatewayAPI          bool
+	Autoscaler          bool
+	OperatorMetrics     bool
+	Strict              bool
+	OnlyIfUnhealthy     bool
+	KeepFullLogs        bool
+	AerospikeNodes      bool
+	KeepManagedFields   bool
+	IncludeKubeSystem   bool
+	Resume              bool
+	// NoDefaultRedaction keeps the environment variable values and Secret values masked by the default
+	// redaction rules
+	NoDefaultRedaction bool
 }
 
-func NewParams(ctx context.Context, kubeconfigPath string, namespaces []string, allNamespaces,
-	clusterScope bool,
+// NewParams creates the Kubernetes clients and returns the Parameters of the given namespaces.
+//
+// Deprecated: use NewParamsFromOptions, which takes all the options of a collection.
+func NewParams(ctx context.Context, kubeconfigPath string, namespaces, excludeNamespaces []string,
+	namespaceRegex string, allNamespaces, clusterScope, verbose bool, logFormat string,
 ) (*Parameters, error) {
-	logger := InitializeConsoleLogger()
+	return NewParamsFromOptions(ctx, &CollectOptions{
+		KubeconfigPath:    kubeconfigPath,
+		Namespaces:        namespaces,
+		ExcludeNamespaces: excludeNamespaces,
+		NamespaceRegex:    namespaceRegex,
+		AllNamespaces:     allNamespaces,
+		ClusterScope:      clusterScope,
+		Verbose:           verbose,
+		LogFormat:         logFormat,
+	})
+}
+
+// newParams creates the logger and the Kubernetes clients of the given options, and returns the Parameters using them.
+func newParams(opts *CollectOptions) (*Parameters, error) {
+	logLevel := zapcore.InfoLevel
+	if opts.Verbose {
+		logLevel = zapcore.DebugLevel
+	}
+
+	logger, err := NewLogger(logLevel, opts.LogFormat, LogWriter(opts.OutputFile))
+	if err != nil {
+		return nil, err
+	}
+
 	logger.Info("Initialized logger")
 
-	k8sClient, clientSet, err := createKubeClients(kubeconfigPath)
+	params := &Parameters{Logger: logger}
+
+	// requests are logged by the logger of params, which is swapped to also write into the collection log file
+	cfg, k8sClient, clientSet, err := createKubeClients(opts.KubeconfigPath, func() *zap.Logger {
+		return params.Logger
+	}, opts.Verbose)
 	if err != nil {
 		return nil, err
 	}
 
 	logger.Info("Created Kubernetes clients")
 
-	params := &Parameters{
-		K8sClient:     k8sClient,
-		ClientSet:     clientSet,
-		Logger:        logger,
-		ClusterScope:  clusterScope,
-		AllNamespaces: allNamespaces,
+	params.K8sClient = k8sClient
+	params.ClientSet = clientSet
+	params.NewExecutor = func(method string, url *url.URL) (remotecommand.Executor, error) {
+		return remotecommand.NewSPDYExecutor(cfg, method, url)
 	}
+	params.ContextName = CurrentContextName(opts.KubeconfigPath)
 
-	if err := params.ValidateNamespaces(ctx, namespaces); err != nil {
-		return nil, err
+	if opts.DumpConfig {
+		DumpConfig(os.Stderr, cfg, params.ContextName)
 	}
 
 	return params, nil
 }
 
-func createKubeClients(kubeconfigPath string) (k8sClient client.Client, clientSet *kubernetes.Clientset, err error) {
-	var cfg *rest.Config
+func createKubeClients(kubeconfigPath string, logger func() *zap.Logger, verbose bool) (cfg *rest.Config,
+	k8sClient client.Client, clientSet *kubernetes.Clientset, err error) {
+	cfg, err = LoadConfig(kubeconfigPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-	if kubeconfigPath != "" {
-		cfg, err = clientcmd.BuildConfigFromFlags("", kubeconfigPath)
-		if err != nil {
-			return nil, nil, err
-		}
-	} else {
-		cfg = runtimeConfig.GetConfigOrDie()
+	if verbose {
+		WrapTransportWithTiming(cfg, logger)
 	}
 
 	scheme := runtime.NewScheme()
 
 	err = clientgoscheme.AddToScheme(scheme)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	clientSet, err = kubernetes.NewForConfig(cfg)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+
+	return cfg, k8sClient, clientSet, nil
+}
+
+// DumpConfig writes the resolved host, context and auth method of the given rest config, so that users can check
+// which cluster and credentials are used. Secrets like tokens, passwords and keys are never written.
+func DumpConfig(w io.Writer, cfg *rest.Config, contextName string) {
+	if contextName == "" {
+		contextName = "none"
+	}
+
+	fmt.Fprintf(w, "Context: %s\n", contextName)
+	fmt.Fprintf(w, "Host: %s\n", cfg.Host)
+	fmt.Fprintf(w, "Auth method: %s\n", authMethod(cfg))
+
+	if cfg.Impersonate.UserName != "" {
+		fmt.Fprintf(w, "Impersonated user: %s\n", cfg.Impersonate.UserName)
+	}
+
+	if cfg.Insecure {
+		fmt.Fprintln(w, "TLS verification: disabled")
+	}
+}
+
+// authMethod returns the method used by the given rest config to authenticate, without its secrets.
+func authMethod(cfg *rest.Config) string {
+	switch {
+	case cfg.ExecProvider != nil:
+		return fmt.Sprintf("exec plugin (%s)", cfg.ExecProvider.Command)
+	case cfg.AuthProvider != nil:
+		return fmt.Sprintf("auth provider (%s)", cfg.AuthProvider.Name)
+	case cfg.BearerTokenFile != "":
+		return fmt.Sprintf("bearer token file (%s)", cfg.BearerTokenFile)
+	case cfg.BearerToken != "":
+		return "bearer token"
+	case cfg.CertFile != "" || len(cfg.CertData) != 0:
+		return "client certificate"
+	case cfg.Username != "":
+		return fmt.Sprintf("basic auth (user %s)", cfg.Username)
+	default:
+		return "none"
+	}
+}
+
+// LoadConfig loads the rest config from the given kubeconfig path. If path is not given, it is loaded from
+// the in-cluster config or the default kubeconfig locations.
+func LoadConfig(kubeconfigPath string) (*rest.Config, error) {
+	if kubeconfigPath != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 	}
 
-	return k8sClient, clientSet, nil
+	return runtimeConfig.GetConfig()
 }
 
+// CurrentContextName returns the current context of the kubeconfig, empty if it can't be resolved.
+func CurrentContextName(kubeconfigPath string) string {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfigPath
+
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return ""
+	}
+
+	return rawConfig.CurrentContext
+}
+
+// SetNamespaceRegex validates and sets the regular expression of the namespaces to be selected.
+func (p *Parameters) SetNamespaceRegex(pattern string) error {
+	namespaceRegex, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid namespace regex %q: %v", pattern, err)
+	}
+
+	p.NamespaceRegex = namespaceRegex
+
+	return nil
+}
+
+// ValidateNamespaces sets the namespaces to perform operation in. These are all the namespaces if AllNamespaces is
+// set, otherwise the given namespaces along with the namespaces matching NamespaceRegex. If both AllNamespaces and
+// NamespaceRegex are set, all the namespaces matching NamespaceRegex are selected.
 func (p *Parameters) ValidateNamespaces(ctx context.Context, namespaces []string) error {
-	if len(namespaces) == 0 && !p.AllNamespaces {
-		return fmt.Errorf("either `namespaces` or `all-namespaces` argument must be provided")
+	if len(namespaces) == 0 && !p.AllNamespaces && p.NamespaceRegex == nil {
+		return fmt.Errorf("either `namespaces`, `namespace-regex` or `all-namespaces` argument must be provided")
+	}
+
+	discoveredNsSet := sets.Set[string]{}
+
+	if p.AllNamespaces || p.NamespaceRegex != nil {
+		var err error
+
+		discoveredNsSet, err = p.discoverNamespaces(ctx)
+		if err != nil {
+			return err
+		}
+
+		if p.AllNamespaces {
+			p.Logger.Info("Capturing for all namespaces",
+				zap.Strings("excluded namespaces", sets.List(p.ExcludeNamespaces)))
+
+			p.Namespaces = discoveredNsSet
+
+			return nil
+		}
+
+		p.Logger.Info("Selected namespaces matching regex", zap.String("regex", p.NamespaceRegex.String()),
+			zap.Strings("namespaces", sets.List(discoveredNsSet)))
+
+		if len(namespaces) == 0 {
+			if discoveredNsSet.Len() == 0 {
+				return fmt.Errorf("no namespace in cluster matches regex %q", p.NamespaceRegex.String())
+			}
+
+			p.Namespaces = discoveredNsSet
+
+			return nil
+		}
 	}
 
 	userNsSet := sets.Set[string]{}
 	userNsSet.Insert(namespaces...)
 
-	allNsSet := sets.Set[string]{}
+	// Verify given namespaces individually, so that list permission on namespaces is not required
+	nonExistentNs := sets.Set[string]{}
+
+	for ns := range userNsSet {
+		if err := p.K8sClient.Get(ctx, types.NamespacedName{Name: ns}, &corev1.Namespace{}); err != nil {
+			switch {
+			case apierrors.IsNotFound(err):
+				nonExistentNs.Insert(ns)
+			case apierrors.IsForbidden(err):
+				p.Logger.Warn("Not able to verify namespace existence, proceeding with it",
+					zap.String("namespace", ns), zap.Error(err))
+			default:
+				return err
+			}
+		}
+	}
+
+	// error out if all the user given namespaces are not present in cluster, and none matches the regex
+	if nonExistentNs.Len() > 0 {
+		if nonExistentNs.Len() == userNsSet.Len() && discoveredNsSet.Len() == 0 {
+			return fmt.Errorf("all given namespaces are not present in cluster")
+		}
+
+		p.Logger.Warn(
+			fmt.Sprintf("namespaces %+v not present in cluster, skipping those namespaces",
+				nonExistentNs.UnsortedList()))
+
+		userNsSet = userNsSet.Difference(nonExistentNs)
+	}
+
+	p.Namespaces = userNsSet.Union(discoveredNsSet)
+
+	return nil
+}
+
+// discoverNamespaces lists the namespaces present in cluster which are not excluded and match NamespaceRegex,
+// if it is set.
+func (p *Parameters) discoverNamespaces(ctx context.Context) (sets.Set[string], error) {
+	nsSet := sets.Set[string]{}
 	namespaceObjs := &corev1.NamespaceList{}
 
 	if err := p.K8sClient.List(ctx, namespaceObjs); err != nil {
-		return err
+		return nil, err
 	}
 
 	for idx := range namespaceObjs.Items {
-		allNsSet.Insert(namespaceObjs.Items[idx].Name)
+		name := namespaceObjs.Items[idx].Name
+
+		// Exclusions apply only to the discovered namespaces, not to explicitly given ones
+		if p.ExcludeNamespaces.Has(name) {
+			continue
+		}
+
+		if p.NamespaceRegex != nil && !p.NamespaceRegex.MatchString(name) {
+			continue
+		}
+
+		nsSet.Insert(name)
 	}
 
-	if p.AllNamespaces {
-		p.Logger.Info("Capturing for all namespaces")
+	return nsSet, nil
+}
 
-		userNsSet = allNsSet
-	} else {
-		nonExistentNs := userNsSet.Difference(allNsSet)
+// SetScope validates and sets the collection scope.
+func (p *Parameters) SetScope(scope string) error {
+	switch scope {
+	case ScopeNamespace, ScopeCluster, ScopeBoth:
+		p.Scope = scope
+		return nil
+	default:
+		return fmt.Errorf("invalid scope %q, must be one of %s, %s or %s", scope,
+			ScopeNamespace, ScopeCluster, ScopeBoth)
+	}
+}
+
+// SetFieldSelectors validates and sets field selectors given in <kind>:<selector> format, where kind is pod or event.
+func (p *Parameters) SetFieldSelectors(selectors []string) error {
+	fieldSelectors := make(map[string]string, len(selectors))
+
+	for _, selector := range selectors {
+		kindName, fieldSelector, found := strings.Cut(selector, ":")
+		if !found {
+			return fmt.Errorf("invalid field selector %q, must be in <kind>:<selector> format", selector)
+		}
+
+		var kind string
+
+		switch strings.ToLower(kindName) {
+		case "pod", "pods":
+			kind = internal.PodKind
+		case "event", "events":
+			kind = internal.EventKind
+		default:
+			return fmt.Errorf("field selector is not supported for kind %q, must be pod or event", kindName)
+		}
+
+		parsedSelector, err := fields.ParseSelector(fieldSelector)
+		if err != nil {
+			return fmt.Errorf("invalid field selector %q: %v", fieldSelector, err)
+		}
 
-		// error out if all the user given namespaces are not present in cluster
-		if nonExistentNs.Len() > 0 {
-			if nonExistentNs.Len() == userNsSet.Len() {
-				return fmt.Errorf("all given namespaces are not present in cluster")
+		for _, requirement := range parsedSelector.Requirements() {
+			if !supportedFieldSelectors[kind].Has(requirement.Field) {
+				return fmt.Errorf("field %q is not supported in field selector of kind %s, supported fields are %v",
+					requirement.Field, kind, sets.List(supportedFieldSelectors[kind]))
 			}
+		}
+
+		fieldSelectors[kind] = parsedSelector.String()
+	}
+
+	p.FieldSelectors = fieldSelectors
+
+	return nil
+}
+
+// SetNodeSelector validates and sets the label selector of collected nodes.
+func (p *Parameters) SetNodeSelector(selector string) error {
+	parsedSelector, err := labels.Parse(selector)
+	if err != nil {
+		return fmt.Errorf("invalid node selector %q: %v", selector, err)
+	}
+
+	p.NodeSelector = parsedSelector.String()
+
+	return nil
+}
+
+// SetLogSelector validates and sets the label selector of the pods whose logs are collected from all namespaces.
+func (p *Parameters) SetLogSelector(selector string) error {
+	parsedSelector, err := labels.Parse(selector)
+	if err != nil {
+		return fmt.Errorf("invalid log selector %q: %v", selector, err)
+	}
+
+	if parsedSelector.Empty() {
+		return fmt.Errorf("log selector must not be empty, it would select all pods of the cluster")
+	}
 
-			p.Logger.Warn(
-				fmt.Sprintf("namespaces %+v not present in cluster, skipping those namespaces",
-					nonExistentNs.UnsortedList()))
+	p.LogSelector = parsedSelector.String()
 
-			userNsSet = userNsSet.Difference(nonExistentNs)
+	return nil
+}
+
+// SetOperatorSelector validates and sets the label selector of the operator Deployment, Pods and ServiceAccount,
+// which are located by their standard names if it is not set.
+func (p *Parameters) SetOperatorSelector(selector string) error {
+	parsedSelector, err := labels.Parse(selector)
+	if err != nil {
+		return fmt.Errorf("invalid operator selector %q: %v", selector, err)
+	}
+
+	if parsedSelector.Empty() {
+		return fmt.Errorf("operator selector must not be empty, it would select all objects as the operator")
+	}
+
+	p.OperatorSelector = parsedSelector.String()
+
+	return nil
+}
+
+// SetOutputNameTemplate validates and sets the template of the file names of collected objects, for example
+// {namespace}_{name}_{uid8}. It must have a name or UID placeholder, so that objects get distinct file names.
+func (p *Parameters) SetOutputNameTemplate(template string) error {
+	placeholders := sets.New(placeholderRegex.FindAllString(template, -1)...)
+
+	if unknown := placeholders.Difference(outputNamePlaceholders); unknown.Len() > 0 {
+		return fmt.Errorf("invalid output name template %q, unknown placeholders %v, supported placeholders are %v",
+			template, sets.List(unknown), sets.List(outputNamePlaceholders))
+	}
+
+	if !placeholders.HasAny(PlaceholderName, PlaceholderUID, PlaceholderUID8) {
+		return fmt.Errorf("invalid output name template %q, must have one of %s, %s or %s placeholders",
+			template, PlaceholderName, PlaceholderUID, PlaceholderUID8)
+	}
+
+	p.OutputNameTemplate = template
+
+	return nil
+}
+
+// SetFileMode validates and sets the mode of written files given as octal string, for example 0640. The mode must
+// let the owner read the files, as they are read back while archiving.
+func (p *Parameters) SetFileMode(mode string) error {
+	fileMode, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || fileMode > 0777 {
+		return fmt.Errorf("invalid file mode %q, must be an octal permission between 0000 and 0777", mode)
+	}
+
+	if fileMode&0400 == 0 {
+		return fmt.Errorf("invalid file mode %q, must allow the owner to read the files", mode)
+	}
+
+	p.FileMode = os.FileMode(fileMode)
+
+	return nil
+}
+
+// SetPerFileGzipThreshold validates and sets the size in bytes above which log files are gzipped individually.
+func (p *Parameters) SetPerFileGzipThreshold(threshold int64) error {
+	if threshold < 0 {
+		return fmt.Errorf("invalid per file gzip threshold %d, must not be negative", threshold)
+	}
+
+	p.PerFileGzipThreshold = threshold
+
+	return nil
+}
+
+// SetPageSize validates and sets the number of objects listed per request.
+func (p *Parameters) SetPageSize(size int64) error {
+	if size < 0 {
+		return fmt.Errorf("invalid page size %d, must not be negative", size)
+	}
+
+	p.PageSize = size
+
+	return nil
+}
+
+// SetExtraKinds reads the extra kinds to be collected from the given YAML file. Kinds already collected by default
+// are rejected when the collection starts.
+func (p *Parameters) SetExtraKinds(fileName string) error {
+	data, err := os.ReadFile(filepath.Clean(fileName))
+	if err != nil {
+		return err
+	}
+
+	var kinds []ExtraKind
+	if err := yaml.UnmarshalStrict(data, &kinds); err != nil {
+		return fmt.Errorf("invalid extra kinds file %s: %v", fileName, err)
+	}
+
+	seen := sets.New[string]()
+
+	for idx := range kinds {
+		if err := validateExtraKind(&kinds[idx], seen); err != nil {
+			return fmt.Errorf("invalid extra kind %q in %s: %v", kinds[idx].Kind, fileName, err)
 		}
+
+		seen.Insert(kinds[idx].Kind)
+	}
+
+	p.ExtraKinds = kinds
+
+	return nil
+}
+
+func validateExtraKind(extraKind *ExtraKind, seen sets.Set[string]) error {
+	if extraKind.Kind == "" || extraKind.Version == "" {
+		return fmt.Errorf("kind and version must be given")
+	}
+
+	if seen.Has(extraKind.Kind) {
+		return fmt.Errorf("kind is given more than once")
+	}
+
+	if extraKind.Scope != ScopeNamespace && extraKind.Scope != ScopeCluster {
+		return fmt.Errorf("invalid scope %q, must be %s or %s", extraKind.Scope, ScopeNamespace, ScopeCluster)
+	}
+
+	return nil
+}
+
+// SetRedactionRules reads the redaction rules from the given YAML file, which extend the default rules.
+// Invalid paths are skipped with a warning when the collection starts.
+func (p *Parameters) SetRedactionRules(fileName string) error {
+	data, err := os.ReadFile(filepath.Clean(fileName))
+	if err != nil {
+		return err
+	}
+
+	var rules []RedactionRule
+	if err := yaml.UnmarshalStrict(data, &rules); err != nil {
+		return fmt.Errorf("invalid redaction rules file %s: %v", fileName, err)
 	}
 
-	p.Namespaces = userNsSet
+	p.RedactionRules = rules
 
 	return nil
 }
 
+// SetInterval sets the interval and the number of latest archives kept of the repeated collections.
+func (p *Parameters) SetInterval(interval time.Duration, count int) error {
+	if interval != 0 && interval < MinInterval {
+		return fmt.Errorf("invalid interval %s, must be at least %s", interval, MinInterval)
+	}
+
+	if count < 0 {
+		return fmt.Errorf("invalid count %d, must not be negative", count)
+	}
+
+	p.Interval, p.Count = interval, count
+
+	return nil
+}
+
+// SetPrometheus sets the address of Prometheus and the time range until now of the metrics queried from it.
+func (p *Parameters) SetPrometheus(url string, queryRange time.Duration) error {
+	if queryRange <= 0 {
+		return fmt.Errorf("invalid prometheus range %s, must be positive", queryRange)
+	}
+
+	p.PrometheusURL, p.PrometheusRange = url, queryRange
+
+	return nil
+}
+
+// SetClusterName limits the collection to the given AerospikeCluster, which must be in the only given namespace.
+func (p *Parameters) SetClusterName(name string) error {
+	if p.AllNamespaces || p.Namespaces.Len() != 1 {
+		return fmt.Errorf("exactly one namespace must be given to collect info of cluster %q", name)
+	}
+
+	p.ClusterName = name
+
+	return nil
+}
+
+// SetTargetNode validates and sets the node whose events are collected along with its conditions. The name is used
+// in the bundle paths, so it must be a valid node name.
+func (p *Parameters) SetTargetNode(name string) error {
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("invalid node name %q: %s", name, strings.Join(errs, ", "))
+	}
+
+	p.TargetNode = name
+
+	return nil
+}
+
+// SetLogGrep validates and sets the regular expression of the container log lines to be captured.
+func (p *Parameters) SetLogGrep(pattern string) error {
+	logGrep, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid log grep pattern %q: %v", pattern, err)
+	}
+
+	p.LogGrep = logGrep
+
+	return nil
+}
+
+// SetLogRedactions validates and sets the regular expressions of the substrings masked in the captured container
+// logs. Empty patterns are ignored, so that an empty pattern disables the default redactions.
+func (p *Parameters) SetLogRedactions(patterns []string) error {
+	p.LogRedactions = nil
+
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid log redaction pattern %q: %v", pattern, err)
+		}
+
+		p.LogRedactions = append(p.LogRedactions, re)
+	}
+
+	return nil
+}
+
+// SetAppendTo validates and sets the existing archive to which the collection is appended.
+func (p *Parameters) SetAppendTo(archive string) error {
+	info, err := os.Stat(archive)
+	if err != nil {
+		return fmt.Errorf("invalid archive to append to %q: %v", archive, err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("invalid archive to append to %q, must be a tar file", archive)
+	}
+
+	p.AppendTo = archive
+
+	return nil
+}
+
+// SetAsinfo validates and sets the info commands run in the Aerospike server containers, along with the service port
+// and the user whose password is read from the given Secret in the namespace of each pod.
+func (p *Parameters) SetAsinfo(commands []string, port int, user, secret string) error {
+	if len(commands) == 0 {
+		return fmt.Errorf("at least one asinfo command is required")
+	}
+
+	for _, command := range commands {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("invalid asinfo commands %q, must not be empty", commands)
+		}
+	}
+
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("invalid asinfo port %d, must be between 1 and 65535", port)
+	}
+
+	if (user == "") != (secret == "") {
+		return fmt.Errorf("asinfo user and password secret must be given together")
+	}
+
+	p.AsinfoCommands = commands
+	p.AsinfoPort = port
+	p.AsinfoUser = user
+	p.AsinfoSecret = secret
+
+	return nil
+}
+
+// SetBaseline validates and sets the archive of a previous collection, against which the changed objects are
+// written in the delta directory of the bundle.
+func (p *Parameters) SetBaseline(archive string) error {
+	info, err := os.Stat(archive)
+	if err != nil {
+		return fmt.Errorf("invalid baseline %q: %v", archive, err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("invalid baseline %q, must be a tar file", archive)
+	}
+
+	p.Baseline = archive
+
+	return nil
+}
+
+// SetCompression validates and sets the compression algorithm of the generated archive.
+func (p *Parameters) SetCompression(compression string) error {
+	switch compression {
+	case CompressionGzip, CompressionZstd:
+		p.Compression = compression
+		return nil
+	default:
+		return fmt.Errorf("invalid compression %q, must be one of %s or %s", compression, CompressionGzip,
+			CompressionZstd)
+	}
+}
+
+// SetFormat validates and sets the format of the collection output.
+func (p *Parameters) SetFormat(format string) error {
+	switch format {
+	case FormatFull, FormatSummary:
+		p.Format = format
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q, must be one of %s or %s", format, FormatFull, FormatSummary)
+	}
+}
+
+// SetPostCommand validates and sets the command run with the archive path after the collection.
+// The command is split on white spaces and run without a shell, so that it is not open to shell injection.
+func (p *Parameters) SetPostCommand(command string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return fmt.Errorf("post command must not be empty")
+	}
+
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return fmt.Errorf("invalid post command %q: %v", fields[0], err)
+	}
+
+	p.PostCommand = fields
+
+	return nil
+}
+
+// NamespaceScoped returns true if namespace scoped resources are to be collected.
+func (p *Parameters) NamespaceScoped() bool {
+	return p.Scope != ScopeCluster
+}
+
+// ClusterScoped returns true if cluster scoped resources are to be collected.
+// If scope is not set, ClusterScope decides it.
+func (p *Parameters) ClusterScoped() bool {
+	if p.Scope == "" {
+		return p.ClusterScope
+	}
+
+	return p.Scope != ScopeNamespace
+}
+
 func InitializeConsoleLogger() *zap.Logger {
+	// console format is always valid
+	logger, _ := NewLogger(zapcore.InfoLevel, LogFormatConsole, os.Stdout)
+
+	return logger
+}
+
+// LogWriter returns the writer of the console logs, which is stderr if the archive is streamed to stdout, so that
+// the logs do not corrupt the stream.
+func LogWriter(outputFile string) io.Writer {
+	if outputFile == StdoutOutputFile {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+// NewLogger returns a logger writing to the given writer in the given format, console or json.
+// Empty format is console.
+func NewLogger(logLevel zapcore.Level, logFormat string, w io.Writer) (*zap.Logger, error) {
 	cfg := zap.NewProductionEncoderConfig()
 	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	consoleEncoder := zapcore.NewConsoleEncoder(cfg)
-	defaultLogLevel := zapcore.InfoLevel
+
+	var encoder zapcore.Encoder
+
+	switch logFormat {
+	case LogFormatConsole, "":
+		encoder = zapcore.NewConsoleEncoder(cfg)
+	case LogFormatJSON:
+		encoder = zapcore.NewJSONEncoder(cfg)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, must be one of %s or %s", logFormat, LogFormatConsole,
+			LogFormatJSON)
+	}
+
 	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), defaultLogLevel),
+		zapcore.NewCore(encoder, zapcore.AddSync(w), logLevel),
 	)
 
-	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.DPanicLevel))
+	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.DPanicLevel)), nil
 }
@@ -0,0 +1,324 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configuration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/testutils"
+)
+
+const limitedUser = "limited-user"
+
+var _ = Describe("Configuration", func() {
+	Context("ValidateNamespaces", func() {
+		It("Should validate explicit namespaces without namespace list permission", func() {
+			clusterRole := &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "namespace-getter"},
+				Rules: []rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"namespaces"},
+						Verbs:     []string{"get"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(testCtx, clusterRole)).To(Succeed())
+
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "namespace-getter"},
+				Subjects: []rbacv1.Subject{
+					{
+						Kind:     rbacv1.UserKind,
+						APIGroup: rbacv1.GroupName,
+						Name:     limitedUser,
+					},
+				},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: rbacv1.GroupName,
+					Kind:     "ClusterRole",
+					Name:     clusterRole.Name,
+				},
+			}
+			Expect(k8sClient.Create(testCtx, clusterRoleBinding)).To(Succeed())
+
+			limitedCfg := rest.CopyConfig(cfg)
+			limitedCfg.Impersonate = rest.ImpersonationConfig{UserName: limitedUser}
+
+			limitedClient, err := client.New(limitedCfg, client.Options{Scheme: scheme})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Validating explicit namespaces")
+			params, err := testutils.NewTestParams(testCtx, limitedClient, nil,
+				[]string{namespace, "nonexistentns"}, false, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(params.Namespaces.UnsortedList()).To(ConsistOf(namespace))
+
+			By("Validating all namespaces")
+			_, err = testutils.NewTestParams(testCtx, limitedClient, nil, nil, true, false)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("SetFieldSelectors", func() {
+		It("Should validate field selectors against the kind", func() {
+			params := &configuration.Parameters{}
+
+			Expect(params.SetFieldSelectors([]string{"pod:status.phase!=Running", "event:type=Warning"})).To(Succeed())
+			Expect(params.FieldSelectors).To(HaveKeyWithValue(internal.PodKind, "status.phase!=Running"))
+			Expect(params.FieldSelectors).To(HaveKeyWithValue(internal.EventKind, "type=Warning"))
+
+			Expect(params.SetFieldSelectors([]string{"status.phase!=Running"})).NotTo(Succeed())
+			Expect(params.SetFieldSelectors([]string{"service:metadata.name=test"})).NotTo(Succeed())
+			Expect(params.SetFieldSelectors([]string{"event:status.phase=Running"})).NotTo(Succeed())
+		})
+	})
+
+	Context("SetPostCommand", func() {
+		It("Should reject empty and unknown commands", func() {
+			params := &configuration.Parameters{}
+
+			Expect(params.SetPostCommand("cp {} /tmp/bundle.tar.gzip")).To(Succeed())
+			Expect(params.PostCommand).To(Equal([]string{"cp", "{}", "/tmp/bundle.tar.gzip"}))
+			Expect(params.ExtraKinds).To(Equal([]configuration.ExtraKind{
+				{Group: "batch", Version: "v1", Kind: "Job", Scope: configuration.ScopeNamespace},
+			}))
+
+			Expect(params.SetPostCommand("  ")).NotTo(Succeed())
+			Expect(params.SetPostCommand("nonexistent-akoctl-command {}")).NotTo(Succeed())
+		})
+	})
+
+	Context("CollectOptions", func() {
+		newParams := func() *configuration.Parameters {
+			return &configuration.Parameters{K8sClient: k8sClient, Logger: configuration.InitializeConsoleLogger()}
+		}
+
+		It("Should decide the scope like the scope and cluster-scope flags", func() {
+			params := newParams()
+			Expect((&configuration.CollectOptions{Namespaces: []string{namespace}}).Apply(testCtx, params)).
+				To(Succeed())
+			Expect(params.Namespaces.UnsortedList()).To(ConsistOf(namespace))
+			Expect(params.NamespaceScoped()).To(BeTrue())
+			Expect(params.ClusterScoped()).To(BeFalse())
+			Expect(params.PageSize).To(Equal(int64(configuration.DefaultPageSize)))
+			Expect(params.PrometheusRange).To(Equal(configuration.DefaultPrometheusRange))
+
+			params = newParams()
+			Expect((&configuration.CollectOptions{Namespaces: []string{namespace}, ClusterScope: true}).
+				Apply(testCtx, params)).To(Succeed())
+			Expect(params.NamespaceScoped()).To(BeTrue())
+			Expect(params.ClusterScoped()).To(BeTrue())
+
+			params = newParams()
+			Expect((&configuration.CollectOptions{Namespaces: []string{namespace}, ClusterScope: true,
+				Scope: configuration.ScopeCluster}).Apply(testCtx, params)).To(Succeed())
+			Expect(params.NamespaceScoped()).To(BeFalse())
+			Expect(params.ClusterScoped()).To(BeTrue())
+		})
+
+		It("Should set the selectors, log and output options", func() {
+			params := newParams()
+			postCommand := "cp {} /tmp/bundle.tar.gzip"
+			allAtOnce := int64(0)
+
+			extraKindsFile := filepath.Join(GinkgoT().TempDir(), "extra-kinds.yaml")
+			Expect(os.WriteFile(extraKindsFile, []byte("- {group: batch, version: v1, kind: Job, scope: namespace}"),
+				0600)).To(Succeed())
+
+			opts := &configuration.CollectOptions{
+				Namespaces:           []string{namespace},
+				ClusterName:          "aerocluster",
+				FieldSelectors:       []string{"pod:status.phase!=Running"},
+				NodeSelector:         "zone=us-east-1a",
+				LogGrep:              "WARNING|ERROR",
+				Compression:          configuration.CompressionZstd,
+				FileMode:             "0640",
+				PostCommand:          &postCommand,
+				ExtraKindsFile:       extraKindsFile,
+				FollowDuration:       time.Minute,
+				MaxNamespaceLogBytes: 1024,
+				KeepManagedFields:    true,
+				PerNamespaceArchive:  true,
+				PageSize:             &allAtOnce,
+			}
+			Expect(opts.Apply(testCtx, params)).To(Succeed())
+
+			Expect(params.ClusterName).To(Equal("aerocluster"))
+			Expect(params.FieldSelectors).To(HaveKeyWithValue(internal.PodKind, "status.phase!=Running"))
+			Expect(params.NodeSelector).To(Equal("zone=us-east-1a"))
+			Expect(params.LogGrep.String()).To(Equal("WARNING|ERROR"))
+			Expect(params.Compression).To(Equal(configuration.CompressionZstd))
+			Expect(params.FileMode).To(Equal(os.FileMode(0640)))
+			Expect(params.PostCommand).To(Equal([]string{"cp", "{}", "/tmp/bundle.tar.gzip"}))
+			Expect(params.FollowDuration).To(Equal(time.Minute))
+			Expect(params.MaxNamespaceLogBytes).To(Equal(int64(1024)))
+			Expect(params.KeepManagedFields).To(BeTrue())
+			Expect(params.PerNamespaceArchive).To(BeTrue())
+			Expect(params.PageSize).To(BeZero())
+			Expect(params.LogGrep.MatchString("INFO")).To(BeFalse())
+		})
+
+		It("Should reject the options rejected by the flags", func() {
+			negative := int64(-1)
+			emptyCommand := ""
+			missingFile := filepath.Join(GinkgoT().TempDir(), "missing.yaml")
+
+			for _, opts := range []*configuration.CollectOptions{
+				{},
+				{Namespaces: []string{namespace}, Scope: "everything"},
+				{Namespaces: []string{namespace}, NamespaceRegex: "["},
+				{Namespaces: []string{namespace}, LogGrep: "("},
+				{Namespaces: []string{namespace}, Compression: "lz4"},
+				{Namespaces: []string{namespace}, Format: "json"},
+				{Namespaces: []string{namespace}, FileMode: "0999"},
+				{Namespaces: []string{namespace}, PerFileGzipThreshold: &negative},
+				{Namespaces: []string{namespace}, PageSize: &negative},
+				{Namespaces: []string{namespace}, PrometheusURL: "http://prometheus:9090", PrometheusRange: -time.Hour},
+				{Namespaces: []string{namespace}, TargetNode: "../node"},
+				{Namespaces: []string{namespace}, PostCommand: &emptyCommand},
+				{Namespaces: []string{namespace}, ExtraKindsFile: missingFile},
+				{Namespaces: []string{namespace}, RedactionRulesFile: missingFile},
+				{AllNamespaces: true, ClusterName: "aerocluster"},
+				{Namespaces: []string{namespace}, Asinfo: true, AsinfoPort: 70000},
+				{Namespaces: []string{namespace}, Asinfo: true, AsinfoUser: "admin"},
+			} {
+				Expect(opts.Apply(testCtx, newParams())).NotTo(Succeed())
+			}
+		})
+	})
+
+	Context("NewLogger", func() {
+		It("Should write valid JSON log lines when json format is given", func() {
+			out := new(bytes.Buffer)
+
+			logger, err := configuration.NewLogger(zapcore.InfoLevel, configuration.LogFormatJSON, out)
+			Expect(err).NotTo(HaveOccurred())
+
+			logger.Info("Initialized logger")
+			logger.Warn("Not able to list events", zap.String("namespace", namespace))
+			Expect(logger.Sync()).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			entry := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(lines[1]), &entry)).To(Succeed())
+			Expect(entry).To(HaveKeyWithValue("level", "warn"))
+			Expect(entry).To(HaveKeyWithValue("msg", "Not able to list events"))
+			Expect(entry).To(HaveKeyWithValue("namespace", namespace))
+
+			for _, line := range lines {
+				Expect(json.Valid([]byte(line))).To(BeTrue())
+			}
+
+			_, err = configuration.NewLogger(zapcore.InfoLevel, "yaml", out)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("DumpConfig", func() {
+		It("Should dump the host, context and auth method of the selected context without secrets", func() {
+			kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster-a
+  cluster:
+    server: https://a.example.com:6443
+- name: cluster-b
+  cluster:
+    server: https://b.example.com:6443
+users:
+- name: user-a
+  user:
+    username: admin
+    password: secret-password
+- name: user-b
+  user:
+    token: secret-token
+contexts:
+- name: ctx-a
+  context:
+    cluster: cluster-a
+    user: user-a
+- name: ctx-b
+  context:
+    cluster: cluster-b
+    user: user-b
+current-context: ctx-b
+`
+			kubeconfigPath := filepath.Join(GinkgoT().TempDir(), "kubeconfig")
+			Expect(os.WriteFile(kubeconfigPath, []byte(kubeconfig), 0600)).To(Succeed())
+
+			dumpCfg, err := configuration.LoadConfig(kubeconfigPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			out := new(bytes.Buffer)
+			configuration.DumpConfig(out, dumpCfg, configuration.CurrentContextName(kubeconfigPath))
+
+			Expect(out.String()).To(ContainSubstring("Context: ctx-b\n"))
+			Expect(out.String()).To(ContainSubstring("Host: https://b.example.com:6443\n"))
+			Expect(out.String()).To(ContainSubstring("Auth method: bearer token\n"))
+			Expect(out.String()).NotTo(ContainSubstring("secret"))
+		})
+	})
+
+	Context("WrapTransportWithTiming", func() {
+		It("Should log timing of every API request", func() {
+			core, logs := observer.New(zapcore.DebugLevel)
+
+			verboseCfg := rest.CopyConfig(cfg)
+			logger := zap.New(zapcore.NewNopCore())
+			configuration.WrapTransportWithTiming(verboseCfg, func() *zap.Logger {
+				return logger
+			})
+
+			By("Swapping the logger after wrapping the transport")
+			logger = zap.New(core)
+
+			verboseClient, err := client.New(verboseCfg, client.Options{Scheme: scheme})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(verboseClient.List(testCtx, &corev1.PodList{}, client.InNamespace(namespace))).To(Succeed())
+
+			entries := logs.FilterMessage("API request").FilterField(zap.String("method", "GET")).All()
+			Expect(entries).NotTo(BeEmpty())
+
+			fields := entries[len(entries)-1].ContextMap()
+			Expect(fields).To(HaveKeyWithValue("status", int64(200)))
+			Expect(fields).To(HaveKey("duration"))
+			Expect(fields["url"]).To(ContainSubstring("/api/v1/namespaces/" + namespace + "/pods"))
+		})
+	})
+})
@@ -0,0 +1,354 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configuration
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// CollectOptions are the options of a collection, used to create Parameters programmatically instead of
+// setting its fields one by one. Zero values keep the defaults of the corresponding collectinfo flags.
+type CollectOptions struct {
+	// Namespaces are collected along with the namespaces selected by AllNamespaces and NamespaceRegex
+	Namespaces        []string
+	ExcludeNamespaces []string
+	// FieldSelectors of pods and events, in <kind>:<selector> format
+	FieldSelectors []string
+	KubeconfigPath string
+	LogFormat      string
+	NamespaceRegex string
+	// ClusterName limits the collection to the given AerospikeCluster
+	ClusterName string
+	// Scope is one of ScopeNamespace, ScopeCluster or ScopeBoth. If empty, ClusterScope decides it.
+	Scope        string
+	NodeSelector string
+	// LogSelector selects the pods of all namespaces whose logs are collected, independent of the namespaces
+	LogSelector string
+	// OperatorSelector selects the operator Deployment, Pods and ServiceAccount, which are located by their standard
+	// names if empty
+	OperatorSelector string
+	// TargetNode is the node whose kubelet events are collected along with its conditions
+	TargetNode      string
+	LogGrep         string
+	NamePrefix      string
+	BundleName      string
+	Compression     string
+	Format          string
+	FileMode        string
+	AppendTo        string
+	Baseline        string
+	ResourceVersion string
+	// PostCommand is run after the collection with the archive path, no command is run if nil. An empty command
+	// is rejected
+	PostCommand *string
+	// ExtraKindsFile is the YAML file of the kinds collected in addition to the default kinds
+	ExtraKindsFile string
+	// RedactionRulesFile is the YAML file of the redaction rules extending the default rules
+	RedactionRulesFile string
+	// OutputFile is the path of the generated archive, or StdoutOutputFile to stream it to stdout with the logs
+	// written to stderr
+	OutputFile string
+	// OutputNameTemplate is the template of the file names of collected objects, for example
+	// {namespace}_{name}_{uid8}. Objects are written as <name>.yaml if empty
+	OutputNameTemplate string
+	// RedactLogs are the regular expressions of the substrings masked in the container logs, DefaultLogRedactions
+	// if nil. An empty list disables the redaction
+	RedactLogs []string
+	// AsinfoCommands are run in the Aerospike server containers with Asinfo on AsinfoPort, DefaultAsinfoCommands
+	// and DefaultAsinfoPort if empty
+	AsinfoCommands []string
+	// AsinfoUser runs the commands with the password in the AsinfoSecret Secret of the namespace of each pod,
+	// commands are run without credentials if empty
+	AsinfoUser   string
+	AsinfoSecret string
+	// PrometheusURL is the address of Prometheus to query the Aerospike metrics from, metrics are not
+	// collected if empty
+	PrometheusURL  string
+	FollowDuration time.Duration
+	// PrometheusRange is the time range until now of the metrics queried from Prometheus, DefaultPrometheusRange
+	// if 0
+	PrometheusRange time.Duration
+	// ContextTimeout limits the collection, the objects collected till then are archived as a partial bundle.
+	// 0 means no limit
+	ContextTimeout time.Duration
+	// ChangedSince limits the collected objects to the ones created or modified within the given duration till now.
+	// 0 means all objects
+	ChangedSince time.Duration
+	// Interval re-runs the collection on the given interval till interrupted, keeping the last Count archives.
+	// 0 runs it once
+	Interval time.Duration
+	// MaxNamespaceLogBytes limits the logs captured per namespace, 0 means no limit
+	MaxNamespaceLogBytes int64
+	// PerFileGzipThreshold is the size in bytes above which log files are gzipped individually with PerFileGzip,
+	// DefaultPerFileGzipThreshold if nil. 0 gzips all the non-empty log files
+	PerFileGzipThreshold *int64
+	// Count is the number of latest archives kept with Interval, 0 keeps all
+	Count int
+	// PageSize is the number of objects listed per request, DefaultPageSize if nil. 0 lists all the objects at once
+	PageSize      *int64
+	LogMaxSize    int
+	AsinfoPort    int
+	AllNamespaces bool
+	ClusterScope  bool
+	Verbose       bool
+	// DumpConfig writes the resolved Kubernetes client config to stderr once the clients are created
+	DumpConfig          bool
+	AerospikeNodes      bool
+	LogTimestamps       bool
+	SinceRestart        bool
+	KeepFullLogs        bool
+	KeepManagedFields   bool
+	ExecConfig          bool
+	Asinfo              bool
+	GatewayAPI          bool
+	Autoscaler          bool
+	OperatorMetrics     bool
+	Strict              bool
+	OnlyIfUnhealthy     bool
+	Consolidate         bool
+	SingleJSON          bool
+	ParallelCompress    bool
+	PerFileGzip         bool
+	PerNamespaceArchive bool
+	IncludeKubeSystem   bool
+	Resume              bool
+	// NoDefaultRedaction keeps the environment variable values and Secret values masked by the default
+	// redaction rules
+	NoDefaultRedaction bool
+}
+
+// NewParamsFromOptions creates the Kubernetes clients and returns the Parameters of the given options. The run
+// time of the Parameters is the time they are created, so that each of them is archived with its own name.
+func NewParamsFromOptions(ctx context.Context, opts *CollectOptions) (*Parameters, error) {
+	params, err := newParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	params.RunTime = time.Now()
+
+	if err := opts.Apply(ctx, params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// Apply validates the options and sets them in the given Parameters, which must have the Kubernetes clients
+// and logger set. Namespaces are validated against the cluster.
+func (o *CollectOptions) Apply(ctx context.Context, p *Parameters) error {
+	p.ExcludeNamespaces = sets.New(o.ExcludeNamespaces...)
+	p.AllNamespaces = o.AllNamespaces
+	p.ClusterScope = o.ClusterScope
+
+	if o.NamespaceRegex != "" {
+		if err := p.SetNamespaceRegex(o.NamespaceRegex); err != nil {
+			return err
+		}
+	}
+
+	if err := p.ValidateNamespaces(ctx, o.Namespaces); err != nil {
+		return err
+	}
+
+	if o.ClusterName != "" {
+		if err := p.SetClusterName(o.ClusterName); err != nil {
+			return err
+		}
+	}
+
+	if o.TargetNode != "" {
+		if err := p.SetTargetNode(o.TargetNode); err != nil {
+			return err
+		}
+	}
+
+	if o.Scope != "" {
+		if err := p.SetScope(o.Scope); err != nil {
+			return err
+		}
+	}
+
+	if err := p.SetFieldSelectors(o.FieldSelectors); err != nil {
+		return err
+	}
+
+	if err := p.SetNodeSelector(o.NodeSelector); err != nil {
+		return err
+	}
+
+	if o.LogSelector != "" {
+		if err := p.SetLogSelector(o.LogSelector); err != nil {
+			return err
+		}
+	}
+
+	if o.OperatorSelector != "" {
+		if err := p.SetOperatorSelector(o.OperatorSelector); err != nil {
+			return err
+		}
+	}
+
+	if o.LogGrep != "" {
+		if err := p.SetLogGrep(o.LogGrep); err != nil {
+			return err
+		}
+	}
+
+	redactLogs := o.RedactLogs
+	if redactLogs == nil {
+		redactLogs = DefaultLogRedactions
+	}
+
+	if err := p.SetLogRedactions(redactLogs); err != nil {
+		return err
+	}
+
+	if o.Compression != "" {
+		if err := p.SetCompression(o.Compression); err != nil {
+			return err
+		}
+	}
+
+	if o.Format != "" {
+		if err := p.SetFormat(o.Format); err != nil {
+			return err
+		}
+	}
+
+	if o.AppendTo != "" {
+		if err := p.SetAppendTo(o.AppendTo); err != nil {
+			return err
+		}
+	}
+
+	if o.Baseline != "" {
+		if err := p.SetBaseline(o.Baseline); err != nil {
+			return err
+		}
+	}
+
+	if o.OutputNameTemplate != "" {
+		if err := p.SetOutputNameTemplate(o.OutputNameTemplate); err != nil {
+			return err
+		}
+	}
+
+	if o.FileMode != "" {
+		if err := p.SetFileMode(o.FileMode); err != nil {
+			return err
+		}
+	}
+
+	if o.Asinfo {
+		commands := o.AsinfoCommands
+		if len(commands) == 0 {
+			commands = DefaultAsinfoCommands
+		}
+
+		port := o.AsinfoPort
+		if port == 0 {
+			port = DefaultAsinfoPort
+		}
+
+		if err := p.SetAsinfo(commands, port, o.AsinfoUser, o.AsinfoSecret); err != nil {
+			return err
+		}
+	}
+
+	threshold := int64(DefaultPerFileGzipThreshold)
+	if o.PerFileGzipThreshold != nil {
+		threshold = *o.PerFileGzipThreshold
+	}
+
+	if err := p.SetPerFileGzipThreshold(threshold); err != nil {
+		return err
+	}
+
+	pageSize := int64(DefaultPageSize)
+	if o.PageSize != nil {
+		pageSize = *o.PageSize
+	}
+
+	if err := p.SetPageSize(pageSize); err != nil {
+		return err
+	}
+
+	if err := p.SetInterval(o.Interval, o.Count); err != nil {
+		return err
+	}
+
+	promRange := o.PrometheusRange
+	if promRange == 0 {
+		promRange = DefaultPrometheusRange
+	}
+
+	if err := p.SetPrometheus(o.PrometheusURL, promRange); err != nil {
+		return err
+	}
+
+	if o.PostCommand != nil {
+		if err := p.SetPostCommand(*o.PostCommand); err != nil {
+			return err
+		}
+	}
+
+	if o.ExtraKindsFile != "" {
+		if err := p.SetExtraKinds(o.ExtraKindsFile); err != nil {
+			return err
+		}
+	}
+
+	if o.RedactionRulesFile != "" {
+		if err := p.SetRedactionRules(o.RedactionRulesFile); err != nil {
+			return err
+		}
+	}
+
+	p.AerospikeNodes = o.AerospikeNodes
+	p.FollowDuration = o.FollowDuration
+	p.ContextTimeout = o.ContextTimeout
+	p.ChangedSince = o.ChangedSince
+	p.MaxNamespaceLogBytes = o.MaxNamespaceLogBytes
+	p.LogTimestamps = o.LogTimestamps
+	p.SinceRestart = o.SinceRestart
+	p.KeepFullLogs = o.KeepFullLogs
+	p.KeepManagedFields = o.KeepManagedFields
+	p.ExecConfig = o.ExecConfig
+	p.Asinfo = o.Asinfo
+	p.GatewayAPI = o.GatewayAPI
+	p.Autoscaler = o.Autoscaler
+	p.OperatorMetrics = o.OperatorMetrics
+	p.Strict = o.Strict
+	p.OnlyIfUnhealthy = o.OnlyIfUnhealthy
+	p.NamePrefix = o.NamePrefix
+	p.BundleName = o.BundleName
+	p.Consolidate = o.Consolidate
+	p.SingleJSON = o.SingleJSON
+	p.ParallelCompress = o.ParallelCompress
+	p.PerFileGzip = o.PerFileGzip
+	p.PerNamespaceArchive = o.PerNamespaceArchive
+	p.IncludeKubeSystem = o.IncludeKubeSystem
+	p.Resume = o.Resume
+	p.NoDefaultRedaction = o.NoDefaultRedaction
+	p.LogMaxSize = o.LogMaxSize
+	p.ResourceVersion = o.ResourceVersion
+
+	return nil
+}
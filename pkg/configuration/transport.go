@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configuration
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+)
+
+// WrapTransportWithTiming wraps the transport of given config to log method, URL, status and duration
+// of every API request at debug level. The logger is taken for each request, so that requests are logged by the
+// current logger of the collection, which also writes into the collection log file.
+func WrapTransportWithTiming(cfg *rest.Config, logger func() *zap.Logger) {
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &timingRoundTripper{logger: logger, delegate: rt}
+	})
+}
+
+type timingRoundTripper struct {
+	logger   func() *zap.Logger
+	delegate http.RoundTripper
+}
+
+func (t *timingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.delegate.RoundTrip(req)
+
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.String()),
+		zap.Duration("duration", time.Since(start)),
+	}
+
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	} else {
+		fields = append(fields, zap.Int("status", resp.StatusCode))
+	}
+
+	t.logger().Debug("API request", fields...)
+
+	return resp, err
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/configuration"
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/internal"
+)
+
+const (
+	// OperatorGroupVersion is the API group version of the operator CRDs
+	OperatorGroupVersion = "asdb.aerospike.com/v1"
+	// OperatorDeploymentName is the name of the operator deployment
+	OperatorDeploymentName = "aerospike-operator-controller-manager"
+	kubectlCMD             = "kubectl"
+)
+
+// Names of the checks run by the doctor command
+const (
+	CheckKubeconfig = "kubeconfig resolvable"
+	CheckReachable  = "cluster reachable"
+	CheckCRDs       = "operator CRDs installed"
+	CheckOperator   = "operator deployment present"
+	CheckKubectl    = "kubectl available"
+)
+
+// Check is the result of a single environment check, along with a hint to fix it if it failed.
+type Check struct {
+	Name   string
+	Detail string
+	Hint   string
+	Passed bool
+}
+
+// Run runs all the checks using the given kubeconfig path and writes the checklist to w.
+// Checks requiring the cluster fail if the kubeconfig can not be resolved.
+func Run(ctx context.Context, kubeconfigPath string, w io.Writer) ([]Check, error) {
+	var clientSet kubernetes.Interface
+
+	check := Check{Name: CheckKubeconfig}
+
+	cfg, err := configuration.LoadConfig(kubeconfigPath)
+	if err == nil {
+		clientSet, err = kubernetes.NewForConfig(cfg)
+	}
+
+	if err != nil {
+		check.Detail = err.Error()
+		check.Hint = "Set --kubeconfig or KUBECONFIG to a valid kubeconfig file, or run inside the cluster"
+	} else {
+		check.Passed = true
+		check.Detail = cfg.Host
+	}
+
+	checks := append([]Check{check}, RunClusterChecks(ctx, clientSet)...)
+
+	return checks, Write(w, checks)
+}
+
+// RunClusterChecks checks the cluster using the given clientset, along with kubectl availability.
+// Cluster checks fail if clientset is nil.
+func RunClusterChecks(ctx context.Context, clientSet kubernetes.Interface) []Check {
+	checks := make([]Check, 0, 4)
+
+	if clientSet == nil {
+		for _, name := range []string{CheckReachable, CheckCRDs, CheckOperator} {
+			checks = append(checks, Check{Name: name, Detail: "skipped", Hint: "Fix the kubeconfig first"})
+		}
+	} else {
+		checks = append(checks, checkReachable(clientSet), checkCRDs(clientSet), checkOperator(ctx, clientSet))
+	}
+
+	return append(checks, checkKubectl())
+}
+
+func checkReachable(clientSet kubernetes.Interface) Check {
+	check := Check{Name: CheckReachable}
+
+	version, err := clientSet.Discovery().ServerVersion()
+	if err != nil {
+		check.Detail = err.Error()
+		check.Hint = "Verify that the apiserver address of the current context is reachable and credentials are valid"
+
+		return check
+	}
+
+	check.Passed = true
+	check.Detail = "kubernetes " + version.GitVersion
+
+	return check
+}
+
+func checkCRDs(clientSet kubernetes.Interface) Check {
+	check := Check{
+		Name: CheckCRDs,
+		Hint: "Install the Aerospike Kubernetes Operator, which installs the AerospikeCluster CRD",
+	}
+
+	resources, err := clientSet.Discovery().ServerResourcesForGroupVersion(OperatorGroupVersion)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	for idx := range resources.APIResources {
+		if resources.APIResources[idx].Kind == internal.AerospikeClusterKind {
+			check.Passed = true
+			check.Hint = ""
+			check.Detail = internal.AerospikeClusterKind + " served at " + OperatorGroupVersion
+
+			return check
+		}
+	}
+
+	check.Detail = internal.AerospikeClusterKind + " not served at " + OperatorGroupVersion
+
+	return check
+}
+
+func checkOperator(ctx context.Context, clientSet kubernetes.Interface) Check {
+	check := Check{
+		Name: CheckOperator,
+		Hint: "Install the operator, or grant list permission on deployments to find it",
+	}
+
+	deployments, err := clientSet.AppsV1().Deployments("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", OperatorDeploymentName).String(),
+	})
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	if len(deployments.Items) == 0 {
+		check.Detail = fmt.Sprintf("deployment %s not found in any namespace", OperatorDeploymentName)
+		return check
+	}
+
+	check.Passed = true
+	check.Hint = ""
+	check.Detail = "found in namespace " + deployments.Items[0].Namespace
+
+	return check
+}
+
+func checkKubectl() Check {
+	check := Check{Name: CheckKubectl}
+
+	path, err := exec.LookPath(kubectlCMD)
+	if err != nil {
+		check.Detail = err.Error()
+		check.Hint = "Add kubectl to PATH, it is used by collectinfo to capture the summary"
+
+		return check
+	}
+
+	check.Passed = true
+	check.Detail = path
+
+	return check
+}
+
+// Write writes the checklist in a table.
+func Write(w io.Writer, checks []Check) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL\tHINT")
+
+	for idx := range checks {
+		status := "FAIL"
+		if checks[idx].Passed {
+			status = "PASS"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", checks[idx].Name, status, checks[idx].Detail, checks[idx].Hint)
+	}
+
+	return tw.Flush()
+}
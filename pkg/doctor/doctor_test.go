@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The aerospike-operator Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctor_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/aerospike/aerospike-kubernetes-operator-ctl/pkg/doctor"
+)
+
+var _ = Describe("Doctor", func() {
+	Context("When operator CRDs are not installed", func() {
+		It("Should fail the CRD check and pass the other cluster checks", func() {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: doctor.OperatorDeploymentName, Namespace: "aerospike"},
+			}
+
+			clientSet := fake.NewSimpleClientset(deployment)
+			clientSet.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+				{
+					GroupVersion: appsv1.SchemeGroupVersion.String(),
+					APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}},
+				},
+			}
+
+			checks := doctor.RunClusterChecks(context.TODO(), clientSet)
+
+			passed := map[string]bool{}
+			for _, check := range checks {
+				passed[check.Name] = check.Passed
+
+				if !check.Passed {
+					Expect(check.Hint).NotTo(BeEmpty())
+				}
+			}
+
+			Expect(passed).To(HaveKeyWithValue(doctor.CheckReachable, true))
+			Expect(passed).To(HaveKeyWithValue(doctor.CheckCRDs, false))
+			Expect(passed).To(HaveKeyWithValue(doctor.CheckOperator, true))
+			Expect(passed).To(HaveKey(doctor.CheckKubectl))
+
+			out := new(bytes.Buffer)
+			Expect(doctor.Write(out, checks)).To(Succeed())
+			Expect(out.String()).To(MatchRegexp(doctor.CheckCRDs + `\s+FAIL`))
+		})
+	})
+
+	Context("When kubeconfig can not be resolved", func() {
+		It("Should fail the kubeconfig and cluster checks", func() {
+			checks, err := doctor.Run(context.TODO(), "wrongpath", new(bytes.Buffer))
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, check := range checks {
+				if check.Name != doctor.CheckKubectl {
+					Expect(check.Passed).To(BeFalse())
+				}
+			}
+		})
+	})
+})
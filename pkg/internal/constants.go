@@ -17,15 +17,26 @@ package internal
 
 const (
 	// Namespace scope resources
-	PodKind              = "Pod"
-	STSKind              = "StatefulSet"
-	DeployKind           = "Deployment"
-	ServiceAccountKind   = "ServiceAccount"
-	ServiceKind          = "Service"
-	AerospikeClusterKind = "AerospikeCluster"
-	PVCKind              = "PersistentVolumeClaim"
-	EventKind            = "Event"
-	RoleBindingKind      = "RoleBinding"
+	PodKind                = "Pod"
+	STSKind                = "StatefulSet"
+	DeployKind             = "Deployment"
+	RSKind                 = "ReplicaSet"
+	DaemonSetKind          = "DaemonSet"
+	ServiceAccountKind     = "ServiceAccount"
+	ServiceKind            = "Service"
+	AerospikeClusterKind   = "AerospikeCluster"
+	PVCKind                = "PersistentVolumeClaim"
+	EventKind              = "Event"
+	RoleBindingKind        = "RoleBinding"
+	ControllerRevisionKind = "ControllerRevision"
+	ConfigMapKind          = "ConfigMap"
+	SecretKind             = "Secret"
+	BackupServiceKind      = "AerospikeBackupService"
+	PDBKind                = "PodDisruptionBudget"
+	PodTemplateKind        = "PodTemplate"
+	IngressKind            = "Ingress"
+	GatewayKind            = "Gateway"
+	HTTPRouteKind          = "HTTPRoute"
 
 	// Cluster scope resources
 	NodeKind               = "Node"
@@ -33,6 +44,13 @@ const (
 	SCKind                 = "StorageClass"
 	MutatingWebhookKind    = "MutatingWebhookConfiguration"
 	ValidatingWebhookKind  = "ValidatingWebhookConfiguration"
+	VolumeAttachmentKind   = "VolumeAttachment"
+	CSINodeKind            = "CSINode"
+	CSIDriverKind          = "CSIDriver"
+	PriorityClassKind      = "PriorityClass"
 	ClusterRoleKind        = "ClusterRole"
 	ClusterRoleBindingKind = "ClusterRoleBinding"
+	NodePoolKind           = "NodePool"
+	NodeClaimKind          = "NodeClaim"
+	NamespaceKind          = "Namespace"
 )
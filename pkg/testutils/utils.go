@@ -13,7 +13,7 @@ import (
 )
 
 func NewTestParams(
-	ctx context.Context, k8sClient client.Client, clientSet *kubernetes.Clientset,
+	ctx context.Context, k8sClient client.Client, clientSet kubernetes.Interface,
 	namespaces []string, allNamespaces, clusterScope bool) (
 	*configuration.Parameters, error) {
 	logger := configuration.InitializeConsoleLogger()